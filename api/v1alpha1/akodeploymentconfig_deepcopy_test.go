@@ -0,0 +1,129 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// populate sets every exported field reachable from v to a non-zero value,
+// allocating pointers, slices and maps on the way
+func populate(v reflect.Value, depth int) {
+	if depth > 10 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		populate(v.Elem(), depth+1)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				populate(v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		populate(v.Index(0), depth+1)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key := reflect.New(v.Type().Key()).Elem()
+		populate(key, depth+1)
+		value := reflect.New(v.Type().Elem()).Elem()
+		populate(value, depth+1)
+		v.SetMapIndex(key, value)
+	case reflect.String:
+		v.SetString("populated")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	}
+}
+
+// mutate changes every value populate has set, in place, so that any memory
+// shared between a deep copy and its original shows up in the original
+func mutate(v reflect.Value, depth int) {
+	if depth > 10 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			mutate(v.Elem(), depth+1)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				mutate(v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			mutate(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			mutate(value, depth+1)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		v.SetString(v.String() + "-mutated")
+	case reflect.Bool:
+		v.SetBool(!v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(v.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(v.Uint() + 1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(v.Float() + 1)
+	}
+}
+
+func populatedAKODeploymentConfig() *AKODeploymentConfig {
+	adc := &AKODeploymentConfig{}
+	populate(reflect.ValueOf(adc), 0)
+	return adc
+}
+
+func TestAKODeploymentConfigDeepCopy(t *testing.T) {
+	g := NewWithT(t)
+
+	original := populatedAKODeploymentConfig()
+	g.Expect(original.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList).To(HaveLen(1))
+	g.Expect(original.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList[0].Cidrs).To(HaveLen(1))
+	g.Expect(original.Spec.Overrides).To(HaveLen(1))
+	g.Expect(original.Spec.Overrides[0].ExtraConfigs).NotTo(BeNil())
+
+	copied, ok := original.DeepCopyObject().(*AKODeploymentConfig)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(copied).To(Equal(original))
+
+	mutate(reflect.ValueOf(copied), 0)
+	g.Expect(copied.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList[0].Cidrs[0]).To(Equal("populated-mutated"))
+	g.Expect(original).To(Equal(populatedAKODeploymentConfig()))
+}
+
+func TestAKODeploymentConfigListDeepCopy(t *testing.T) {
+	g := NewWithT(t)
+
+	original := &AKODeploymentConfigList{Items: []AKODeploymentConfig{*populatedAKODeploymentConfig()}}
+	copied, ok := original.DeepCopyObject().(*AKODeploymentConfigList)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(copied).To(Equal(original))
+
+	mutate(reflect.ValueOf(copied), 0)
+	g.Expect(original.Items[0]).To(Equal(*populatedAKODeploymentConfig()))
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// MergeSpecs returns base with the non-zero fields of override applied on
+// top of it. Nested structs are merged field by field while pointers, slices
+// and maps from override replace the ones in base as a whole.
+func MergeSpecs(base, override AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+	merged := base.DeepCopy()
+	mergeValue(reflect.ValueOf(merged).Elem(), reflect.ValueOf(override.DeepCopy()).Elem())
+	return *merged
+}
+
+func mergeValue(dst, src reflect.Value) {
+	if src.Kind() == reflect.Struct {
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+		return
+	}
+	if !src.IsZero() {
+		dst.Set(src)
+	}
+}
+
+// EffectiveSpec returns the spec used for the Cluster with clusterLabels,
+// which is Spec merged with the Overrides selecting the Cluster. The
+// returned spec has no Overrides.
+func (r *AKODeploymentConfig) EffectiveSpec(clusterLabels map[string]string) AKODeploymentConfigSpec {
+	spec := r.baseSpec()
+	for i := range r.Spec.Overrides {
+		if r.Spec.Overrides[i].Selects(clusterLabels) {
+			spec = MergeSpecs(spec, r.Spec.Overrides[i].spec())
+		}
+	}
+	return spec
+}
+
+// EffectiveSpecs returns Spec followed by Spec merged with each of the
+// Overrides, which are the specs the AVI resources shared by the Clusters
+// are configured for. The returned specs have no Overrides.
+func (r *AKODeploymentConfig) EffectiveSpecs() []AKODeploymentConfigSpec {
+	base := r.baseSpec()
+	specs := []AKODeploymentConfigSpec{base}
+	for i := range r.Spec.Overrides {
+		specs = append(specs, MergeSpecs(base, r.Spec.Overrides[i].spec()))
+	}
+	return specs
+}
+
+// baseSpec returns Spec without the Overrides
+func (r *AKODeploymentConfig) baseSpec() AKODeploymentConfigSpec {
+	spec := r.Spec
+	spec.Overrides = nil
+	return spec
+}
+
+// Selects checks if the override applies to the Cluster with clusterLabels
+func (o *AKODeploymentConfigOverride) Selects(clusterLabels map[string]string) bool {
+	selector, err := metav1.LabelSelectorAsSelector(&o.ClusterSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(clusterLabels))
+}
+
+// spec returns the override as a spec where only the overridden fields are set
+func (o *AKODeploymentConfigOverride) spec() AKODeploymentConfigSpec {
+	spec := AKODeploymentConfigSpec{ServiceEngineGroup: o.ServiceEngineGroup}
+	if o.DataNetwork != nil {
+		spec.DataNetwork = *o.DataNetwork
+	}
+	if o.ExtraConfigs != nil {
+		spec.ExtraConfigs = *o.ExtraConfigs
+	}
+	return spec
+}
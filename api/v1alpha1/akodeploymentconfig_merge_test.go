@@ -0,0 +1,192 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func mergeTestBaseSpec() AKODeploymentConfigSpec {
+	return AKODeploymentConfigSpec{
+		CloudName:          "test-cloud",
+		Controller:         "10.0.0.1",
+		ServiceEngineGroup: "Default-Group",
+		ClusterSelector: v1.LabelSelector{
+			MatchLabels: map[string]string{"test": "true"},
+		},
+		AdminCredentialRef: &SecretRef{Name: "admin-secret", Namespace: "default"},
+		DataNetwork: DataNetwork{
+			Name: "VM Network",
+			CIDR: "10.0.0.0/24",
+		},
+		ControlPlaneNetwork: ControlPlaneNetwork{Name: "cp-network", CIDR: "10.1.0.0/24"},
+		ExtraConfigs: ExtraConfigs{
+			Log:                  AKOLogConfig{LogLevel: "INFO", LogFile: "ako.log"},
+			EnableEvents:         pointer.BoolPtr(true),
+			BlockedNamespaceList: []string{"kube-system"},
+		},
+	}
+}
+
+func TestMergeSpecs(t *testing.T) {
+	testcases := []struct {
+		name     string
+		override AKODeploymentConfigSpec
+		expected func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec
+	}{
+		{
+			name:     "empty override should keep the base spec",
+			override: AKODeploymentConfigSpec{},
+			expected: func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				return base
+			},
+		},
+		{
+			name: "partial override should only replace non-zero fields",
+			override: AKODeploymentConfigSpec{
+				ServiceEngineGroup: "Override-SEG",
+				DataNetwork:        DataNetwork{CIDR: "10.2.0.0/24"},
+				ExtraConfigs: ExtraConfigs{
+					Log:          AKOLogConfig{LogLevel: "DEBUG"},
+					EnableEvents: pointer.BoolPtr(false),
+				},
+			},
+			expected: func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				base.ServiceEngineGroup = "Override-SEG"
+				base.DataNetwork.CIDR = "10.2.0.0/24"
+				base.ExtraConfigs.Log.LogLevel = "DEBUG"
+				base.ExtraConfigs.EnableEvents = pointer.BoolPtr(false)
+				return base
+			},
+		},
+		{
+			name: "full override should replace all fields",
+			override: AKODeploymentConfigSpec{
+				CloudName:          "override-cloud",
+				Controller:         "10.0.0.2",
+				ServiceEngineGroup: "Override-SEG",
+				ClusterSelector: v1.LabelSelector{
+					MatchLabels: map[string]string{"override": "true"},
+				},
+				AdminCredentialRef: &SecretRef{Name: "override-secret", Namespace: "tkg-system"},
+				DataNetwork: DataNetwork{
+					Name: "override-network",
+					CIDR: "10.2.0.0/24",
+				},
+				ControlPlaneNetwork: ControlPlaneNetwork{Name: "override-cp-network", CIDR: "10.3.0.0/24"},
+				ExtraConfigs: ExtraConfigs{
+					Log:                  AKOLogConfig{LogLevel: "DEBUG", LogFile: "override.log"},
+					EnableEvents:         pointer.BoolPtr(false),
+					BlockedNamespaceList: []string{"default"},
+				},
+			},
+			expected: func(_ AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				return AKODeploymentConfigSpec{
+					CloudName:          "override-cloud",
+					Controller:         "10.0.0.2",
+					ServiceEngineGroup: "Override-SEG",
+					ClusterSelector: v1.LabelSelector{
+						MatchLabels: map[string]string{"override": "true"},
+					},
+					AdminCredentialRef: &SecretRef{Name: "override-secret", Namespace: "tkg-system"},
+					DataNetwork: DataNetwork{
+						Name: "override-network",
+						CIDR: "10.2.0.0/24",
+					},
+					ControlPlaneNetwork: ControlPlaneNetwork{Name: "override-cp-network", CIDR: "10.3.0.0/24"},
+					ExtraConfigs: ExtraConfigs{
+						Log:                  AKOLogConfig{LogLevel: "DEBUG", LogFile: "override.log"},
+						EnableEvents:         pointer.BoolPtr(false),
+						BlockedNamespaceList: []string{"default"},
+					},
+				}
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			base := mergeTestBaseSpec()
+			merged := MergeSpecs(base, tc.override)
+			g.Expect(merged).To(Equal(tc.expected(mergeTestBaseSpec())))
+			// base spec should not be modified
+			g.Expect(base).To(Equal(mergeTestBaseSpec()))
+		})
+	}
+}
+
+func TestEffectiveSpec(t *testing.T) {
+	g := NewWithT(t)
+	adc := &AKODeploymentConfig{Spec: mergeTestBaseSpec()}
+	g.Expect(adc.EffectiveSpec(map[string]string{"test": "true"})).To(Equal(mergeTestBaseSpec()))
+
+	adc.Spec.Overrides = []AKODeploymentConfigOverride{
+		{
+			ClusterSelector: v1.LabelSelector{
+				MatchLabels: map[string]string{"seg": "override"},
+			},
+			ServiceEngineGroup: "Override-SEG",
+		},
+		{
+			ClusterSelector: v1.LabelSelector{
+				MatchLabels: map[string]string{"network": "override"},
+			},
+			DataNetwork:  &DataNetwork{Name: "override-network", CIDR: "10.2.0.0/24"},
+			ExtraConfigs: &ExtraConfigs{Log: AKOLogConfig{LogLevel: "DEBUG"}},
+		},
+	}
+	spec := adc.Spec.DeepCopy()
+
+	testcases := []struct {
+		name          string
+		clusterLabels map[string]string
+		expected      func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec
+	}{
+		{
+			name:          "cluster selected by no override should use the base spec",
+			clusterLabels: map[string]string{"test": "true"},
+			expected: func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				return base
+			},
+		},
+		{
+			name:          "cluster selected by one override should use its fields",
+			clusterLabels: map[string]string{"test": "true", "seg": "override"},
+			expected: func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				base.ServiceEngineGroup = "Override-SEG"
+				return base
+			},
+		},
+		{
+			name:          "cluster selected by several overrides should merge them in order",
+			clusterLabels: map[string]string{"test": "true", "seg": "override", "network": "override"},
+			expected: func(base AKODeploymentConfigSpec) AKODeploymentConfigSpec {
+				base.ServiceEngineGroup = "Override-SEG"
+				base.DataNetwork = DataNetwork{Name: "override-network", CIDR: "10.2.0.0/24"}
+				base.ExtraConfigs.Log.LogLevel = "DEBUG"
+				return base
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(adc.EffectiveSpec(tc.clusterLabels)).To(Equal(tc.expected(mergeTestBaseSpec())))
+			// spec should not be modified
+			g.Expect(adc.Spec).To(Equal(*spec))
+		})
+	}
+
+	specs := adc.EffectiveSpecs()
+	g.Expect(specs).To(HaveLen(3))
+	g.Expect(specs[0]).To(Equal(mergeTestBaseSpec()))
+	g.Expect(specs[1].ServiceEngineGroup).To(Equal("Override-SEG"))
+	g.Expect(specs[2].DataNetwork.Name).To(Equal("override-network"))
+}
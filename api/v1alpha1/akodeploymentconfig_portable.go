@@ -0,0 +1,75 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// portableAKODeploymentConfig is the exported form of an AKODeploymentConfig,
+// it only carries what's needed to re-create the object in another
+// management cluster
+type portableAKODeploymentConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        portableObjectMeta      `json:"metadata"`
+	Spec            AKODeploymentConfigSpec `json:"spec"`
+}
+
+type portableObjectMeta struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// MarshalToPortableJSON serializes the AKODeploymentConfig into JSON which can
+// be imported into another management cluster. Runtime metadata such as
+// uid, resourceVersion, finalizers, managedFields and the status are stripped.
+func MarshalToPortableJSON(adc *AKODeploymentConfig) ([]byte, error) {
+	if adc == nil {
+		return nil, errors.New("AKODeploymentConfig is nil")
+	}
+	portable := portableAKODeploymentConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       "AKODeploymentConfig",
+		},
+		Metadata: portableObjectMeta{
+			Name:        adc.Name,
+			Labels:      adc.Labels,
+			Annotations: adc.Annotations,
+		},
+		Spec: adc.Spec,
+	}
+	return json.Marshal(portable)
+}
+
+// UnmarshalFromPortableJSON reconstructs an AKODeploymentConfig from the output
+// of MarshalToPortableJSON, the returned object is ready to be created
+func UnmarshalFromPortableJSON(data []byte) (*AKODeploymentConfig, error) {
+	portable := &portableAKODeploymentConfig{}
+	if err := json.Unmarshal(data, portable); err != nil {
+		return nil, err
+	}
+	if portable.Kind != "" && portable.Kind != "AKODeploymentConfig" {
+		return nil, errors.New("unexpected kind " + portable.Kind + ", expecting AKODeploymentConfig")
+	}
+	if portable.Metadata.Name == "" {
+		return nil, errors.New("AKODeploymentConfig name is empty")
+	}
+	return &AKODeploymentConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       "AKODeploymentConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        portable.Metadata.Name,
+			Labels:      portable.Metadata.Labels,
+			Annotations: portable.Metadata.Annotations,
+		},
+		Spec: portable.Spec,
+	}, nil
+}
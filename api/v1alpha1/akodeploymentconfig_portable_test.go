@@ -0,0 +1,142 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestPortableJSONRoundtrip(t *testing.T) {
+	g := NewWithT(t)
+	apiServerPort := 8080
+
+	adc := &AKODeploymentConfig{
+		ObjectMeta: v1.ObjectMeta{
+			Name:              "test-adc",
+			Labels:            map[string]string{"app": "ako"},
+			Annotations:       map[string]string{"description": "exported"},
+			UID:               types.UID("2a0f6c8e-0d1a-4c66-9c1b-3c6f3b0f4a11"),
+			ResourceVersion:   "12345",
+			Generation:        3,
+			CreationTimestamp: v1.Now(),
+			Finalizers:        []string{AkoDeploymentConfigFinalizer},
+			ManagedFields:     []v1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: AKODeploymentConfigSpec{
+			CloudName:            "test-cloud",
+			Controller:           "10.0.0.1",
+			ControllerVersion:    "21.1.4",
+			AVIControllerVersion: "20.1.3",
+			ServiceEngineGroup:   "Default-Group",
+			ClusterSelector: v1.LabelSelector{
+				MatchLabels: map[string]string{"test": "true"},
+			},
+			WorkloadCredentialRef:   &SecretRef{Name: "workload-secret", Namespace: "default"},
+			AdminCredentialRef:      &SecretRef{Name: "admin-secret", Namespace: "default"},
+			CertificateAuthorityRef: &SecretRef{Name: "ca-secret", Namespace: "default"},
+			Tenant:                  AVITenant{Context: "Provider", Name: "admin"},
+			DataNetwork: DataNetwork{
+				Name:    "VM Network",
+				CIDR:    "10.0.0.0/24",
+				IPPools: []IPPool{{Start: "10.0.0.10", End: "10.0.0.20", Type: "V4"}},
+			},
+			ControlPlaneNetwork: ControlPlaneNetwork{Name: "cp-network", CIDR: "10.1.0.0/24"},
+			ExtraConfigs: ExtraConfigs{
+				PrimaryInstance:        pointer.BoolPtr(true),
+				Log:                    AKOLogConfig{LogLevel: "DEBUG", PersistentVolumeClaim: "pvc", MountPath: "/log", LogFile: "ako.log"},
+				FullSyncFrequency:      "1800",
+				ApiServerPort:          &apiServerPort,
+				EnableEvents:           pointer.BoolPtr(true),
+				DisableStaticRouteSync: pointer.BoolPtr(false),
+				CniPlugin:              "antrea",
+				EnableEVH:              pointer.BoolPtr(true),
+				Layer7Only:             pointer.BoolPtr(false),
+				NamespaceSelector:      NamespaceSelector{LabelKey: "key", LabelValue: "value"},
+				ServicesAPI:            pointer.BoolPtr(true),
+				VIPPerNamespace:        pointer.BoolPtr(true),
+				IstioEnabled:           pointer.BoolPtr(true),
+				BlockedNamespaceList:   []string{"kube-system"},
+				IpFamily:               "V4",
+				UseDefaultSecretsOnly:  pointer.BoolPtr(true),
+				NetworksConfig: NetworksConfig{
+					EnableRHI:     pointer.BoolPtr(true),
+					BGPPeerLabels: []string{"peer1"},
+					NsxtT1LR:      "/infra/tier-1s/t1",
+				},
+				IngressConfigs: AKOIngressConfig{
+					DisableIngressClass:      pointer.BoolPtr(false),
+					DefaultIngressController: pointer.BoolPtr(true),
+					ServiceType:              "NodePortLocal",
+					ShardVSSize:              "LARGE",
+					PassthroughShardSize:     "SMALL",
+					NodeNetworkList: []NodeNetwork{
+						{NetworkName: "node-network-1", Cidrs: []string{"10.0.0.0/24", "192.168.0.0/24"}},
+						{NetworkName: "node-network-2", Cidrs: []string{"10.2.0.0/24"}},
+					},
+					NoPGForSNI: pointer.BoolPtr(true),
+					EnableMCI:  pointer.BoolPtr(true),
+				},
+				L4Configs:        AKOL4Config{DefaultDomain: "example.com", AutoFQDN: "default"},
+				NodePortSelector: NodePortSelector{Key: "node", Value: "true"},
+				Rbac:             AKORbacConfig{PspPolicyAPIVersion: "policy/v1beta1", PspEnabled: pointer.BoolPtr(true)},
+				VIPConfig: VIPConfig{
+					VIPNetworkName: "vip-network",
+					VIPNetworkCIDR: "10.1.0.0/24",
+				},
+			},
+		},
+		Status: AKODeploymentConfigStatus{
+			ObservedGeneration: 3,
+			Conditions:         clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: "True"}},
+		},
+	}
+
+	data, err := MarshalToPortableJSON(adc)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	restored, err := UnmarshalFromPortableJSON(data)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	// all spec fields survive the cycle
+	g.Expect(restored.Spec).To(Equal(adc.Spec))
+	g.Expect(restored.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList).To(Equal(adc.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList))
+
+	// identity is kept
+	g.Expect(restored.Name).To(Equal(adc.Name))
+	g.Expect(restored.Labels).To(Equal(adc.Labels))
+	g.Expect(restored.Annotations).To(Equal(adc.Annotations))
+	g.Expect(restored.APIVersion).To(Equal(GroupVersion.String()))
+	g.Expect(restored.Kind).To(Equal("AKODeploymentConfig"))
+
+	// runtime metadata and status are stripped
+	g.Expect(restored.UID).To(BeEmpty())
+	g.Expect(restored.ResourceVersion).To(BeEmpty())
+	g.Expect(restored.Generation).To(BeZero())
+	g.Expect(restored.CreationTimestamp.IsZero()).To(BeTrue())
+	g.Expect(restored.Finalizers).To(BeEmpty())
+	g.Expect(restored.ManagedFields).To(BeEmpty())
+	g.Expect(restored.Status).To(Equal(AKODeploymentConfigStatus{}))
+}
+
+func TestPortableJSONInvalidInput(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := MarshalToPortableJSON(nil)
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = UnmarshalFromPortableJSON([]byte("not json"))
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = UnmarshalFromPortableJSON([]byte(`{"kind":"Cluster","metadata":{"name":"test"}}`))
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = UnmarshalFromPortableJSON([]byte(`{"kind":"AKODeploymentConfig","metadata":{}}`))
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -30,6 +30,12 @@ type AKODeploymentConfigSpec struct {
 	// this value can be auto detected and corrected.
 	ControllerVersion string `json:"controllerVersion,omitempty"`
 
+	// AVIControllerVersion is the minimum AVI Controller version required by the
+	// AKO version being deployed. When set, the reconciler marks this AKODeploymentConfig
+	// with the ControllerVersionIncompatible condition if the AVI Controller is older.
+	// +optional
+	AVIControllerVersion string `json:"aviControllerVersion,omitempty"`
+
 	// ServiceEngineGroup is the group name of Service Engine that's to be used by the set
 	// of AKO Deployments
 	ServiceEngineGroup string `json:"serviceEngineGroup"`
@@ -75,6 +81,18 @@ type AKODeploymentConfigSpec struct {
 	//
 	CertificateAuthorityRef SecretReference `json:"certificateAuthorityRef"`
 
+	// AVICABundleRef points to a Secret in the management cluster holding a PEM
+	// encoded CA bundle in its ca.crt key, e.g. the private CAs used by AVI
+	// Controllers in enterprise environments. The bundle is appended to the
+	// AVI Controller's CA AKO trusts in the workload clusters
+	// +optional
+	AVICABundleRef SecretReference `json:"aviCABundleRef,omitempty"`
+
+	// AKONamespace is the namespace AKO is deployed in the workload clusters.
+	// It must be a valid DNS label and can't be changed. Default value: avi-system.
+	// +optional
+	AKONamespace string `json:"akoNamespace,omitempty"`
+
 	// The AVI tenant for the current AKODeploymentConfig
 	// This field is optional.
 	// +optional
@@ -94,6 +112,12 @@ type AKODeploymentConfigSpec struct {
 	//
 	// +optional
 	ExtraConfigs ExtraConfigs `json:"extraConfigs,omitempty"`
+
+	// Overrides hold spec fields which take precedence over the spec for the
+	// Clusters they select. The overrides selecting a Cluster are merged in
+	// order into the spec, only their non-zero fields are merged.
+	// +optional
+	Overrides []AKODeploymentConfigOverride `json:"overrides,omitempty"`
 }
 
 // ExtraConfigs contains extra configurations for AKO Deployment
@@ -118,6 +142,12 @@ type ExtraConfigs struct {
 	// +optional
 	ApiServerPort *int `json:"apiServerPort,omitempty"`
 
+	// Replicas is the number of AKO replicas, default value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Defines Enable or disable Event broadcasting via AKO
 	// +optional
 	EnableEvents *bool `json:"enableEvents,omitempty"`
@@ -203,6 +233,23 @@ type ExtraConfigs struct {
 	// Rbac specifies the configuration for AKO Rbac
 	// +optional
 	Rbac AKORbacConfig `json:"rbac,omitempty"`
+
+	// VIPConfig specifies a network for AVI to place the VIPs on, which is different
+	// from the data network. VIPs are placed on the data network if empty
+	// +optional
+	VIPConfig VIPConfig `json:"vipConfig,omitempty"`
+}
+
+// VIPConfig describes the network AVI places the VIPs on
+type VIPConfig struct {
+	// VIPNetworkName is the name of the AVI network VIPs are placed on
+	// +optional
+	VIPNetworkName string `json:"vipNetworkName,omitempty"`
+
+	// VIPNetworkCIDR is the CIDR of the VIP network, it's required when
+	// VIPNetworkName is set
+	// +optional
+	VIPNetworkCIDR string `json:"vipNetworkCIDR,omitempty"`
 }
 
 // NameSpaceSelector contains label key and value used for namespace migration
@@ -401,6 +448,51 @@ type AKODeploymentConfigStatus struct {
 	// Conditions defines current state of the AKODeploymentConfig.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// ManagedResources lists the resources created in the selected clusters,
+	// they are deleted when the AKODeploymentConfig is deleted since owner
+	// references can't span clusters.
+	// +optional
+	ManagedResources []ManagedResource `json:"managedResources,omitempty"`
+
+	// SelectedClusters lists the namespace/name of the Clusters selected the
+	// last time the AKODeploymentConfig was reconciled, AKO is removed from
+	// the ones which aren't selected by any AKODeploymentConfig anymore.
+	// +optional
+	SelectedClusters []string `json:"selectedClusters,omitempty"`
+
+	// ClusterStatuses reports the state of AKO in each Cluster selected the
+	// last time the AKODeploymentConfig was reconciled, so a Cluster where
+	// AKO fails to be deployed can be told apart from the other ones.
+	// +optional
+	ClusterStatuses []ClusterStatus `json:"clusterStatuses,omitempty"`
+}
+
+// ClusterStatus is the state of AKO in a selected cluster
+type ClusterStatus struct {
+	// ClusterName is the name of the Cluster.
+	ClusterName string `json:"clusterName"`
+	// Namespace is the namespace of the Cluster.
+	Namespace string `json:"namespace"`
+	// Conditions defines the current state of AKO in the Cluster.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ManagedResource references a resource created in a selected cluster
+type ManagedResource struct {
+	// Cluster is the namespace/name of the Cluster the resource is created in.
+	Cluster string `json:"cluster"`
+	// Group is the API group of the resource, empty for the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+	// Namespace of the resource, empty for cluster scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the resource.
+	Name string `json:"name"`
 }
 
 // +kubebuilder:object:root=true
@@ -416,6 +508,49 @@ type AKODeploymentConfig struct {
 	Status AKODeploymentConfigStatus `json:"status,omitempty"`
 }
 
+// AKODeploymentConfigOverride overrides spec fields of an AKODeploymentConfig
+// for some of its Clusters
+type AKODeploymentConfigOverride struct {
+	// ClusterSelector selects the Clusters the override applies to, among the
+	// ones selected by the AKODeploymentConfig. It must match the Cluster
+	// labels.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// ServiceEngineGroup overrides the AVI Service Engine Group of the
+	// selected Clusters
+	// +optional
+	ServiceEngineGroup string `json:"serviceEngineGroup,omitempty"`
+
+	// DataNetwork overrides the AVI Data Network of the selected Clusters
+	// +optional
+	DataNetwork *DataNetwork `json:"dataNetwork,omitempty"`
+
+	// ExtraConfigs overrides the extra configurations of AKO in the selected
+	// Clusters
+	// +optional
+	ExtraConfigs *ExtraConfigs `json:"extraConfigs,omitempty"`
+}
+
+// GetConditions returns the conditions of the AKODeploymentConfig
+func (c *AKODeploymentConfig) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions sets the conditions of the AKODeploymentConfig
+func (c *AKODeploymentConfig) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
+// GetAKONamespace returns the namespace AKO is deployed in the workload
+// clusters, AKODeploymentConfigs created before AKONamespace was added are not
+// defaulted so AviNamespace is returned for them
+func (c *AKODeploymentConfig) GetAKONamespace() string {
+	if c.Spec.AKONamespace == "" {
+		return AviNamespace
+	}
+	return c.Spec.AKONamespace
+}
+
 // +kubebuilder:object:root=true
 
 // AKODeploymentConfigList contains a list of AKODeploymentConfig
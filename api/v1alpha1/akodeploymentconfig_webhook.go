@@ -8,19 +8,24 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"reflect"
 	"regexp"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/util/feature"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/features"
 )
 
 // log is for logging in this package.
@@ -31,14 +36,36 @@ var runTest bool
 
 const controllerVersionRegex = `^\d+(\.\d+)*$`
 
+const (
+	// maxAKOReplicas is the max number of AKO replicas
+	maxAKOReplicas = 3
+)
+
+// akoLogLevels are the log levels supported by AKO
+var akoLogLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
 func (r *AKODeploymentConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return (&AKODeploymentConfigWebhook{}).SetupWebhookWithManager(mgr)
+}
+
+// AKODeploymentConfigWebhook registers the mutating and validating webhooks
+// of AKODeploymentConfigs
+// +kubebuilder:object:generate=false
+type AKODeploymentConfigWebhook struct {
+	// DryRun makes the mutating webhook allow the requests without changing
+	// the objects, and preview the changes in its responses instead
+	DryRun bool
+}
+
+// SetupWebhookWithManager registers the webhooks to the webhook server of mgr
+func (w *AKODeploymentConfigWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	kclient = mgr.GetClient()
+	mgr.GetWebhookServer().Register(mutatingWebhookPath, &webhook.Admission{Handler: &akoDeploymentConfigMutator{dryRun: w.DryRun}})
 	return ctrl.NewWebhookManagedBy(mgr).
-		For(r).
+		For(&AKODeploymentConfig{}).
 		Complete()
 }
 
-//+kubebuilder:webhook:verbs=create;update,path=/validate-networking-tkg-tanzu-vmware-com-v1alpha1-akodeploymentconfig,mutating=true,failurePolicy=fail,groups=networking.tkg.tanzu.vmware.com,resources=akodeploymentconfigs,versions=v1alpha1,name=vakodeploymentconfig.kb.io,sideEffects=None,admissionReviewVersions=v1;v1alpha1
 //+kubebuilder:webhook:verbs=create;update;delete,path=/validate-networking-tkg-tanzu-vmware-com-v1alpha1-akodeploymentconfig,mutating=false,failurePolicy=fail,groups=networking.tkg.tanzu.vmware.com,resources=akodeploymentconfigs,versions=v1alpha1,name=vakodeploymentconfig.kb.io, sideEffects=None, admissionReviewVersions=v1;v1alpha1
 
 var _ webhook.Validator = &AKODeploymentConfig{}
@@ -50,6 +77,9 @@ func (r *AKODeploymentConfig) ValidateCreate() error {
 	var allErrs field.ErrorList
 	allErrs = append(allErrs, r.validateClusterSelector(nil)...)
 	allErrs = append(allErrs, r.validateAVI(nil)...)
+	allErrs = append(allErrs, r.validateExtraConfigs()...)
+	allErrs = append(allErrs, r.validateAKONamespace(nil)...)
+	allErrs = append(allErrs, r.validateOverride()...)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -67,6 +97,9 @@ func (r *AKODeploymentConfig) ValidateUpdate(old runtime.Object) error {
 	if oldADC != nil {
 		allErrs = append(allErrs, r.validateClusterSelector(oldADC)...)
 		allErrs = append(allErrs, r.validateAVI(oldADC)...)
+		allErrs = append(allErrs, r.validateExtraConfigs()...)
+		allErrs = append(allErrs, r.validateAKONamespace(oldADC)...)
+		allErrs = append(allErrs, r.validateOverride()...)
 	}
 	if len(allErrs) == 0 {
 		return nil
@@ -128,6 +161,12 @@ func (r *AKODeploymentConfig) validateClusterSelector(old *AKODeploymentConfig)
 func (r *AKODeploymentConfig) validateAVI(old *AKODeploymentConfig) field.ErrorList {
 	var allErrs field.ErrorList
 
+	// check avi controller address format
+	if err := validateController(r.Spec.Controller); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "controller"), r.Spec.Controller, err.Error()))
+		return allErrs
+	}
+
 	// check avi related secret
 	adminCredential := &corev1.Secret{}
 	if err := r.validateAviSecret(adminCredential, r.Spec.AdminCredentialRef); err != nil {
@@ -182,13 +221,13 @@ func (r *AKODeploymentConfig) validateAVI(old *AKODeploymentConfig) field.ErrorL
 		if err := r.validateAviCloud(); err != nil {
 			allErrs = append(allErrs, err)
 		}
-		if err := r.validateAviServiceEngineGroup(); err != nil {
+		if err := validateAviServiceEngineGroup(field.NewPath("spec"), r.Spec); err != nil {
 			allErrs = append(allErrs, err)
 		}
 		if err := r.validateAviControlPlaneNetworks(); err != nil {
 			allErrs = append(allErrs, err...)
 		}
-		if err := r.validateAviDataNetworks(); err != nil {
+		if err := validateAviDataNetworks(field.NewPath("spec"), r.Spec); err != nil {
 			allErrs = append(allErrs, err...)
 		}
 	} else {
@@ -200,7 +239,7 @@ func (r *AKODeploymentConfig) validateAVI(old *AKODeploymentConfig) field.ErrorL
 			}
 		}
 		if old.Spec.ServiceEngineGroup != r.Spec.ServiceEngineGroup {
-			if err := r.validateAviServiceEngineGroup(); err != nil {
+			if err := validateAviServiceEngineGroup(field.NewPath("spec"), r.Spec); err != nil {
 				allErrs = append(allErrs, err)
 			}
 		}
@@ -214,11 +253,36 @@ func (r *AKODeploymentConfig) validateAVI(old *AKODeploymentConfig) field.ErrorL
 		}
 		if (old.Spec.DataNetwork.Name != r.Spec.DataNetwork.Name) ||
 			(old.Spec.DataNetwork.CIDR != r.Spec.DataNetwork.CIDR) {
-			if err := r.validateAviDataNetworks(); err != nil {
+			if err := validateAviDataNetworks(field.NewPath("spec"), r.Spec); err != nil {
 				allErrs = append(allErrs, err...)
 			}
 		}
 	}
+	allErrs = append(allErrs, r.validateAviOverrides(old)...)
+	return allErrs
+}
+
+// validateAviOverrides checks the Service Engine Groups and Data Networks of
+// AKODeploymentConfig object's overrides exist in NSX Advanced Load Balancer,
+// like the ones of the spec. When old is not nil, only the changed overrides
+// are checked.
+func (r *AKODeploymentConfig) validateAviOverrides(old *AKODeploymentConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, override := range r.Spec.Overrides {
+		if old != nil && i < len(old.Spec.Overrides) && reflect.DeepEqual(old.Spec.Overrides[i], override) {
+			continue
+		}
+		fldPath := field.NewPath("spec", "overrides").Index(i)
+		spec := MergeSpecs(r.baseSpec(), override.spec())
+		if override.ServiceEngineGroup != "" {
+			if err := validateAviServiceEngineGroup(fldPath, spec); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		if override.DataNetwork != nil {
+			allErrs = append(allErrs, validateAviDataNetworks(fldPath, spec)...)
+		}
+	}
 	return allErrs
 }
 
@@ -256,6 +320,26 @@ func (r *AKODeploymentConfig) validateAviControllerVersion() (string, *field.Err
 	return controllerVersion, nil
 }
 
+// validateController checks NSX Advanced Load Balancer controller address is either
+// a valid IPv4/IPv6 address or a valid RFC 1123 hostname, optionally followed by a
+// port, e.g. avi.example.com:9443
+func validateController(s string) error {
+	if s == "" {
+		return fmt.Errorf("controller address should not be empty")
+	}
+	host, _, err := aviclient.ParseControllerAddress(s)
+	if err != nil {
+		return fmt.Errorf("controller address should be a host optionally followed by a port: %s", err.Error())
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(strings.ToLower(host)); len(errs) != 0 {
+		return fmt.Errorf("controller address should be a valid IP address or hostname: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 // validateAviAccount checks if using inputs can connect to avi controller or not
 func (r *AKODeploymentConfig) validateAviAccount(username, password, certificate, version string) (aviclient.Client, *field.Error) {
 	aviClient, err := aviclient.NewAviClient(&aviclient.AviClientConfig{
@@ -282,10 +366,11 @@ func (r *AKODeploymentConfig) validateAviCloud() *field.Error {
 	return nil
 }
 
-// validateAviServiceEngineGroup checks input Servcie Engine Group valid or not
-func (r *AKODeploymentConfig) validateAviServiceEngineGroup() *field.Error {
-	if _, err := aviClient.ServiceEngineGroupGetByName(r.Spec.ServiceEngineGroup, r.Spec.CloudName); err != nil {
-		return field.Invalid(field.NewPath("spec", "serviceEngineGroup"), r.Spec.ServiceEngineGroup,
+// validateAviServiceEngineGroup checks input Servcie Engine Group of spec valid or not,
+// fldPath is the path of spec
+func validateAviServiceEngineGroup(fldPath *field.Path, spec AKODeploymentConfigSpec) *field.Error {
+	if _, err := aviClient.ServiceEngineGroupGetByName(spec.ServiceEngineGroup, spec.CloudName); err != nil {
+		return field.Invalid(fldPath.Child("serviceEngineGroup"), spec.ServiceEngineGroup,
 			"failed to get service engine group from avi controller:"+err.Error())
 	}
 	return nil
@@ -317,51 +402,137 @@ func (r *AKODeploymentConfig) validateAviControlPlaneNetworks() field.ErrorList
 // Data Plane Network name existing or not
 // CIDR format valid or not
 // IPPools format valid or not
-func (r *AKODeploymentConfig) validateAviDataNetworks() field.ErrorList {
+// fldPath is the path of spec
+func validateAviDataNetworks(fldPath *field.Path, spec AKODeploymentConfigSpec) field.ErrorList {
 	var allErrs field.ErrorList
 	// check data network name
-	if _, err := aviClient.NetworkGetByName(r.Spec.DataNetwork.Name, r.Spec.CloudName); err != nil {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "name"),
-			r.Spec.DataNetwork.Name,
-			"failed to get data plane network "+r.Spec.DataNetwork.Name+" from avi controller:"+err.Error()))
+	if _, err := aviClient.NetworkGetByName(spec.DataNetwork.Name, spec.CloudName); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "name"),
+			spec.DataNetwork.Name,
+			"failed to get data plane network "+spec.DataNetwork.Name+" from avi controller:"+err.Error()))
 	}
 	// check network cidr
-	_, cidr, err := net.ParseCIDR(r.Spec.DataNetwork.CIDR)
+	_, cidr, err := net.ParseCIDR(spec.DataNetwork.CIDR)
 	if err != nil {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "cidr"),
-			r.Spec.DataNetwork.CIDR,
-			"data plane network cidr "+r.Spec.DataNetwork.CIDR+" is not valid:"+err.Error()))
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "cidr"),
+			spec.DataNetwork.CIDR,
+			"data plane network cidr "+spec.DataNetwork.CIDR+" is not valid:"+err.Error()))
+	} else if cidr.IP.To4() == nil && !feature.DefaultFeatureGate.Enabled(features.IPv6DataNetwork) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "cidr"),
+			spec.DataNetwork.CIDR,
+			"data plane network cidr "+spec.DataNetwork.CIDR+" is an IPv6 network, which requires the "+string(features.IPv6DataNetwork)+" feature gate"))
 	}
 	// check data network ip pools
-	for _, ipPool := range r.Spec.DataNetwork.IPPools {
+	for _, ipPool := range spec.DataNetwork.IPPools {
 		ipStart := net.ParseIP(ipPool.Start)
 		ipEnd := net.ParseIP(ipPool.End)
 		if ipStart == nil {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "ipPools"),
-				r.Spec.DataNetwork.IPPools,
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "ipPools"),
+				spec.DataNetwork.IPPools,
 				"ip pool address"+ipPool.Start+" is not valid"))
 		}
 		if ipEnd == nil {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "ipPools"),
-				r.Spec.DataNetwork.IPPools,
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "ipPools"),
+				spec.DataNetwork.IPPools,
 				"ip pool address"+ipPool.End+" is not valid"))
 		}
-		if !cidr.Contains(ipStart) || !cidr.Contains(ipEnd) {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "ipPools"),
-				r.Spec.DataNetwork.IPPools,
+		if cidr != nil && (!cidr.Contains(ipStart) || !cidr.Contains(ipEnd)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "ipPools"),
+				spec.DataNetwork.IPPools,
 				"Range ["+ipPool.Start+","+ipPool.End+"] is not in cidr"))
 		}
 		if bytes.Compare(ipStart, ipEnd) > 0 {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dataNetwork", "ipPools"),
-				r.Spec.DataNetwork.IPPools,
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dataNetwork", "ipPools"),
+				spec.DataNetwork.IPPools,
 				ipPool.Start+" is greater than "+ipPool.End))
 		}
 		// TODO:(xudongl) will wait for AKO support v6 address to uncomment this
 		// if ippool.Type != addrType {
-		// 	return field.Invalid(field.NewPath("spec", "dataNetwork", "ipPools"),
-		// 		r.Spec.DataNetwork.IPPools,
+		// 	return field.Invalid(fldPath.Child("dataNetwork", "ipPools"),
+		// 		spec.DataNetwork.IPPools,
 		// 		"data plane network ip pools type is not aligned with cidr")
 		// }
 	}
 	return allErrs
 }
+
+// validateAKONamespace checks AKODeploymentConfig object's AKO namespace is a valid DNS label
+// when old is nil, it is used for AKODeploymentConfig object create, otherwise it is used for AKODeploymentConfig
+// object update
+func (r *AKODeploymentConfig) validateAKONamespace(old *AKODeploymentConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	// when update AKODeploymentConfig object, AKO namespace should be immutable as the AKO
+	// resources in the old namespace would be left behind
+	if old != nil && old.GetAKONamespace() != r.GetAKONamespace() {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "akoNamespace"),
+			r.Spec.AKONamespace,
+			"field should not be changed"))
+		return allErrs
+	}
+	if ns := r.Spec.AKONamespace; ns != "" {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "akoNamespace"), ns, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateExtraConfigs checks AKODeploymentConfig object's extra configs are valid or not,
+// they're checked in the spec merged with each override so that the overridden ones are
+// checked as well
+func (r *AKODeploymentConfig) validateExtraConfigs() field.ErrorList {
+	var allErrs field.ErrorList
+	for i, spec := range r.EffectiveSpecs() {
+		fldPath := field.NewPath("spec", "extraConfigs")
+		if i > 0 {
+			fldPath = field.NewPath("spec", "overrides").Index(i - 1).Child("extraConfigs")
+		}
+		allErrs = append(allErrs, validateExtraConfigs(fldPath, spec.ExtraConfigs)...)
+	}
+	return allErrs
+}
+
+func validateExtraConfigs(fldPath *field.Path, extraConfigs ExtraConfigs) field.ErrorList {
+	var allErrs field.ErrorList
+	if level := extraConfigs.Log.LogLevel; level != "" && !isAKOLogLevel(level) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("log", "logLevel"),
+			level, akoLogLevels))
+	}
+	if replicas := extraConfigs.Replicas; replicas != nil && (*replicas < 1 || *replicas > maxAKOReplicas) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"),
+			*replicas,
+			fmt.Sprintf("replicas should be between 1 and %d", maxAKOReplicas)))
+	}
+	if vipConfig := extraConfigs.VIPConfig; vipConfig.VIPNetworkName != "" {
+		if _, _, err := net.ParseCIDR(vipConfig.VIPNetworkCIDR); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("vipConfig", "vipNetworkCIDR"),
+				vipConfig.VIPNetworkCIDR,
+				"vip network cidr should be a valid CIDR when vip network name is set: "+err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateOverride checks AKODeploymentConfig object's overrides select clusters with
+// valid selectors, their AVI fields are checked by validateAviOverrides
+func (r *AKODeploymentConfig) validateOverride() field.ErrorList {
+	var allErrs field.ErrorList
+	for i, override := range r.Spec.Overrides {
+		fldPath := field.NewPath("spec", "overrides").Index(i)
+		if _, err := metav1.LabelSelectorAsSelector(&override.ClusterSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("clusterSelector"),
+				override.ClusterSelector,
+				err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func isAKOLogLevel(level string) bool {
+	for _, l := range akoLogLevels {
+		if level == l {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const mutatingWebhookPath = "/mutate-networking-tkg-tanzu-vmware-com-v1alpha1-akodeploymentconfig"
+
+//+kubebuilder:webhook:verbs=create;update,path=/mutate-networking-tkg-tanzu-vmware-com-v1alpha1-akodeploymentconfig,mutating=true,failurePolicy=fail,groups=networking.tkg.tanzu.vmware.com,resources=akodeploymentconfigs,versions=v1alpha1,name=makodeploymentconfig.kb.io,sideEffects=None,admissionReviewVersions=v1;v1alpha1
+
+// LastChange is the value of the LastChangeByAnnotation
+// +kubebuilder:object:generate=false
+type LastChange struct {
+	// User is the name of the user who changed the spec
+	User string `json:"user"`
+	// Timestamp is when the spec was changed, in RFC3339 format
+	Timestamp string `json:"timestamp"`
+	// Diff is the diff between the old and the new spec
+	Diff string `json:"diff"`
+}
+
+// akoDeploymentConfigMutator defaults AKODeploymentConfigs. It also logs their
+// spec changes with the user who made them, and records the last change in the
+// LastChangeByAnnotation, which is done here as validating webhooks can't change
+// the object.
+type akoDeploymentConfigMutator struct {
+	decoder *admission.Decoder
+	// dryRun makes the mutator preview its changes in the response instead of
+	// applying them
+	dryRun bool
+}
+
+var _ admission.DecoderInjector = &akoDeploymentConfigMutator{}
+
+// InjectDecoder implements admission.DecoderInjector
+func (m *akoDeploymentConfigMutator) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler
+func (m *akoDeploymentConfigMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &AKODeploymentConfig{}
+	if err := m.decoder.DecodeRaw(req.Object, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the defaults are set first, so an update omitting the defaulted
+	// fields isn't recorded as a change
+	obj.setDefaults()
+
+	if req.Operation == admissionv1.Update {
+		old := &AKODeploymentConfig{}
+		if err := m.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := obj.recordLastChange(old, req.UserInfo.Username); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if m.dryRun {
+		return previewMutation(req.Object.Raw, marshaled)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// previewMutation allows the request without changing the object. The merge
+// patch between the original and the mutated object is returned as a warning,
+// which kubectl shows, and in the MutationPreviewAuditAnnotation of the audit
+// event of the request.
+func previewMutation(original, mutated []byte) admission.Response {
+	patch, err := jsonpatch.CreateMergePatch(original, mutated)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	akoDeploymentConfigLog.V(1).Info("mutation preview", "patch", string(patch))
+	resp := admission.Allowed("")
+	if string(patch) == "{}" {
+		return resp
+	}
+	resp.AuditAnnotations = map[string]string{MutationPreviewAuditAnnotation: string(patch)}
+	return resp.WithWarnings("mutation preview: " + string(patch))
+}
+
+// setDefaults sets the default values of the AKODeploymentConfig
+func (r *AKODeploymentConfig) setDefaults() {
+	if r.Spec.AKONamespace == "" {
+		r.Spec.AKONamespace = AviNamespace
+	}
+	if r.Spec.ExtraConfigs.Log.LogLevel == "" {
+		r.Spec.ExtraConfigs.Log.LogLevel = "INFO"
+	}
+	if r.Spec.ExtraConfigs.Replicas == nil {
+		replicas := int32(1)
+		r.Spec.ExtraConfigs.Replicas = &replicas
+	}
+}
+
+// recordLastChange logs the diff between the old and the current spec, and
+// records it in the LastChangeByAnnotation when the spec is changed
+func (r *AKODeploymentConfig) recordLastChange(old *AKODeploymentConfig, username string) error {
+	diff := cmp.Diff(old.Spec, r.Spec)
+	if diff == "" {
+		return nil
+	}
+	akoDeploymentConfigLog.Info("spec changed", "name", r.Name, "user", username, "diff", diff)
+
+	value, err := json.Marshal(LastChange{
+		User:      username,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Diff:      diff,
+	})
+	if err != nil {
+		return err
+	}
+	if r.Annotations == nil {
+		r.Annotations = make(map[string]string)
+	}
+	r.Annotations[LastChangeByAnnotation] = string(value)
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func mutatingRequest(g *WithT, operation admissionv1.Operation, username string, old, obj *AKODeploymentConfig) admission.Request {
+	oldRaw, err := json.Marshal(old)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	objRaw, err := json.Marshal(obj)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: operation,
+		UserInfo:  authenticationv1.UserInfo{Username: username},
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+		Object:    runtime.RawExtension{Raw: objRaw},
+	}}
+}
+
+func TestAKODeploymentConfigMutator(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	decoder, err := admission.NewDecoder(scheme)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	mutator := &akoDeploymentConfigMutator{}
+	g.Expect(mutator.InjectDecoder(decoder)).To(Succeed())
+
+	old := &AKODeploymentConfig{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       "AKODeploymentConfig",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test",
+		},
+		Spec: AKODeploymentConfigSpec{
+			CloudName:          "test-cloud",
+			Controller:         "10.23.122.1",
+			ServiceEngineGroup: "Default-SEG",
+			AKONamespace:       AviNamespace,
+			ExtraConfigs: ExtraConfigs{
+				Log:      AKOLogConfig{LogLevel: "INFO"},
+				Replicas: pointer.Int32(1),
+			},
+		},
+	}
+
+	t.Run("spec changed", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.ServiceEngineGroup = "New-SEG"
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Update, "alice", old, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(HaveLen(1))
+		g.Expect(resp.Patches[0].Path).To(Equal("/metadata/annotations"))
+
+		annotations, ok := resp.Patches[0].Value.(map[string]interface{})
+		g.Expect(ok).To(BeTrue())
+		value, ok := annotations[LastChangeByAnnotation].(string)
+		g.Expect(ok).To(BeTrue())
+		change := LastChange{}
+		g.Expect(json.Unmarshal([]byte(value), &change)).To(Succeed())
+		g.Expect(change.User).To(Equal("alice"))
+		g.Expect(change.Timestamp).NotTo(BeEmpty())
+		g.Expect(change.Diff).To(ContainSubstring("Default-SEG"))
+		g.Expect(change.Diff).To(ContainSubstring("New-SEG"))
+	})
+
+	t.Run("spec not changed", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Labels = map[string]string{"foo": "bar"}
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Update, "alice", old, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(BeEmpty())
+	})
+
+	t.Run("spec not changed without the defaulted fields", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.AKONamespace = ""
+		obj.Spec.ExtraConfigs.Log.LogLevel = ""
+		obj.Spec.ExtraConfigs.Replicas = nil
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Update, "alice", old, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(HaveLen(3))
+		for _, patch := range resp.Patches {
+			g.Expect(patch.Path).To(HavePrefix("/spec/"))
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.AKONamespace = ""
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Create, "alice", &AKODeploymentConfig{}, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(HaveLen(1))
+		g.Expect(resp.Patches[0].Path).To(Equal("/spec/akoNamespace"))
+		g.Expect(resp.Patches[0].Value).To(Equal(AviNamespace))
+	})
+
+	t.Run("create without log level", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.ExtraConfigs.Log.LogLevel = ""
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Create, "alice", &AKODeploymentConfig{}, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(HaveLen(1))
+		g.Expect(resp.Patches[0].Path).To(Equal("/spec/extraConfigs/log/logLevel"))
+		g.Expect(resp.Patches[0].Value).To(Equal("INFO"))
+	})
+
+	t.Run("create without replicas", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.ExtraConfigs.Replicas = nil
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Create, "alice", &AKODeploymentConfig{}, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(HaveLen(1))
+		g.Expect(resp.Patches[0].Path).To(Equal("/spec/extraConfigs/replicas"))
+		g.Expect(resp.Patches[0].Value).To(BeNumerically("==", 1))
+	})
+
+	t.Run("create with defaulted fields set", func(t *testing.T) {
+		g := NewWithT(t)
+		obj := old.DeepCopy()
+		obj.Spec.AKONamespace = "custom-ns"
+		obj.Spec.ExtraConfigs.Log.LogLevel = "DEBUG"
+		obj.Spec.ExtraConfigs.Replicas = pointer.Int32(2)
+
+		resp := mutator.Handle(context.Background(), mutatingRequest(g, admissionv1.Create, "alice", &AKODeploymentConfig{}, obj))
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patches).To(BeEmpty())
+		g.Expect(resp.Warnings).To(BeEmpty())
+	})
+
+}
+
+func TestAKODeploymentConfigMutatorDryRun(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	decoder, err := admission.NewDecoder(scheme)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	mutator := &akoDeploymentConfigMutator{dryRun: true}
+	g.Expect(mutator.InjectDecoder(decoder)).To(Succeed())
+	mutatingWebhook := &webhook.Admission{Handler: mutator}
+	g.Expect(mutatingWebhook.InjectLogger(logr.Discard())).To(Succeed())
+
+	// review sends the AKODeploymentConfig to the dry-run webhook, and returns
+	// its response with the admission response it contains
+	review := func(g *WithT, obj *AKODeploymentConfig) *admissionv1.AdmissionResponse {
+		req := mutatingRequest(g, admissionv1.Create, "alice", &AKODeploymentConfig{}, obj)
+		req.UID = "test-uid"
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: v1.TypeMeta{
+				APIVersion: admissionv1.SchemeGroupVersion.String(),
+				Kind:       "AdmissionReview",
+			},
+			Request: &req.AdmissionRequest,
+		})
+		g.Expect(err).ShouldNot(HaveOccurred())
+		httpReq := httptest.NewRequest(http.MethodPost, mutatingWebhookPath, bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		mutatingWebhook.ServeHTTP(recorder, httpReq)
+
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		resp := admissionv1.AdmissionReview{}
+		g.Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).To(Succeed())
+		g.Expect(resp.Response).NotTo(BeNil())
+		return resp.Response
+	}
+
+	obj := &AKODeploymentConfig{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       "AKODeploymentConfig",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test",
+		},
+		Spec: AKODeploymentConfigSpec{
+			CloudName:          "test-cloud",
+			Controller:         "10.23.122.1",
+			ServiceEngineGroup: "Default-SEG",
+			ExtraConfigs: ExtraConfigs{
+				Log:      AKOLogConfig{LogLevel: "INFO"},
+				Replicas: pointer.Int32(1),
+			},
+		},
+	}
+
+	t.Run("defaulted fields are previewed", func(t *testing.T) {
+		g := NewWithT(t)
+		resp := review(g, obj)
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patch).To(BeEmpty())
+		g.Expect(resp.PatchType).To(BeNil())
+
+		preview := map[string]interface{}{}
+		g.Expect(json.Unmarshal([]byte(resp.AuditAnnotations[MutationPreviewAuditAnnotation]), &preview)).To(Succeed())
+		g.Expect(preview).To(Equal(map[string]interface{}{
+			"spec": map[string]interface{}{"akoNamespace": AviNamespace},
+		}))
+		g.Expect(resp.Warnings).To(Equal([]string{"mutation preview: " + resp.AuditAnnotations[MutationPreviewAuditAnnotation]}))
+	})
+
+	t.Run("nothing to preview", func(t *testing.T) {
+		g := NewWithT(t)
+		defaulted := obj.DeepCopy()
+		defaulted.Spec.AKONamespace = AviNamespace
+		resp := review(g, defaulted)
+		g.Expect(resp.Allowed).To(BeTrue())
+		g.Expect(resp.Patch).To(BeEmpty())
+		g.Expect(resp.AuditAnnotations).To(BeEmpty())
+		g.Expect(resp.Warnings).To(BeEmpty())
+	})
+}
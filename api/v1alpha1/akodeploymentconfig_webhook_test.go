@@ -5,13 +5,19 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/features"
 	"github.com/vmware/alb-sdk/go/models"
+	"github.com/vmware/alb-sdk/go/session"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/component-base/featuregate"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -117,6 +123,26 @@ func configureAVIController() {
 	})
 }
 
+// rejectServiceEngineGroup makes the fake avi client fail to get the service engine group name
+func rejectServiceEngineGroup(name string) {
+	aviClient.(*aviclient.FakeAviClient).ServiceEngineGroup.SetGetByNameFn(func(n string, options ...session.ApiOptionsParams) (*models.ServiceEngineGroup, error) {
+		if n == name {
+			return nil, errors.New("can't find service engine group")
+		}
+		return &models.ServiceEngineGroup{Name: pointer.StringPtr(n)}, nil
+	})
+}
+
+// rejectNetwork makes the fake avi client fail to get the network name
+func rejectNetwork(name string) {
+	aviClient.(*aviclient.FakeAviClient).Network.SetGetByNameFn(func(n string, options ...session.ApiOptionsParams) (*models.Network, error) {
+		if n == name {
+			return nil, errors.New("can't find network")
+		}
+		return &models.Network{Name: pointer.StringPtr(n)}, nil
+	})
+}
+
 func TestCreateNewAKODeploymentConfig(t *testing.T) {
 	staticAdminSecret, staticCASecret, staticADC, g := beforeAll(t)
 	testcases := []struct {
@@ -125,6 +151,7 @@ func TestCreateNewAKODeploymentConfig(t *testing.T) {
 		certificateSecret *corev1.Secret
 		adc               *AKODeploymentConfig
 		customizeInput    ModifyTestCaseInputFunc
+		featureGates      map[featuregate.Feature]bool
 		expectErr         bool
 	}{
 		{
@@ -317,10 +344,330 @@ func TestCreateNewAKODeploymentConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name:              "should throw error if data plane network is IPv6 without the IPv6DataNetwork feature gate",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.DataNetwork = DataNetwork{
+					Name: "fake-data-plane",
+					CIDR: "fd00:10::/64",
+				}
+				return adminSecret, certificateSecret, adc
+			},
+			featureGates: map[featuregate.Feature]bool{features.IPv6DataNetwork: false},
+			expectErr:    true,
+		},
+		{
+			name:              "IPv6 data plane network should pass webhook validation with the IPv6DataNetwork feature gate",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.DataNetwork = DataNetwork{
+					Name: "fake-data-plane",
+					CIDR: "fd00:10::/64",
+				}
+				return adminSecret, certificateSecret, adc
+			},
+			featureGates: map[featuregate.Feature]bool{features.IPv6DataNetwork: true},
+			expectErr:    false,
+		},
+		{
+			name:              "custom vip network should pass webhook validation",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.VIPConfig = VIPConfig{VIPNetworkName: "vip-network", VIPNetworkCIDR: "10.1.0.0/24"}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if custom vip network cidr is invalid",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.VIPConfig = VIPConfig{VIPNetworkName: "vip-network", VIPNetworkCIDR: "10.1.0.0"}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should not throw error if AKO namespace is a valid DNS label",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.AKONamespace = "custom-ns"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if AKO namespace is not a valid DNS label",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.AKONamespace = "Custom.NS"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should not throw error if AKO log level is supported",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.Log.LogLevel = "DEBUG"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if AKO log level is not supported",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.Log.LogLevel = "debug"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden AKO log level is not supported",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ExtraConfigs: &ExtraConfigs{Log: AKOLogConfig{LogLevel: "TRACE"}},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should not throw error if AKO replicas is in range",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.Replicas = pointer.Int32(3)
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if AKO replicas is out of range",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.ExtraConfigs.Replicas = pointer.Int32(4)
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden AKO replicas is out of range",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ExtraConfigs: &ExtraConfigs{Replicas: pointer.Int32(0)},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden vip network cidr is not valid",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ExtraConfigs: &ExtraConfigs{VIPConfig: VIPConfig{VIPNetworkName: "vip-network", VIPNetworkCIDR: "test"}},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if controller address contains space",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi controller.example.com"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should not throw error if controller address is a host with port",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi.example.com:9443"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should not throw error if controller address is an IP address with port",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "10.0.0.1:9443"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should not throw error if controller address is a bare host",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi.example.com"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should not throw error if controller address is a bare IPv6 address",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "fd00::1"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if controller port is not a number",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi.example.com:https"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if controller port is out of range",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi.example.com:70000"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if controller port is empty",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Controller = "avi.example.com:"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "override of the service engine group should pass webhook validation",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ClusterSelector: v1.LabelSelector{
+						MatchLabels: map[string]string{"seg": "override"},
+					},
+					ServiceEngineGroup: "Override-SEG",
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
+		{
+			name:              "should throw error if override cluster selector is not valid",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ClusterSelector: v1.LabelSelector{
+						MatchExpressions: []v1.LabelSelectorRequirement{{Key: "seg", Operator: "Equals"}},
+					},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden service engine group does not exist",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				rejectServiceEngineGroup("Override-SEG")
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ServiceEngineGroup: "Override-SEG",
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden data network does not exist",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				rejectNetwork("override-network")
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					DataNetwork: &DataNetwork{Name: "override-network", CIDR: "11.0.0.0/24"},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "should throw error if overridden data network cidr is not valid",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			adc:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					DataNetwork: &DataNetwork{Name: "override-network", CIDR: "test"},
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
+			for f, enabled := range tc.featureGates {
+				defer featuregatetesting.SetFeatureGateDuringTest(t, feature.DefaultFeatureGate, f, enabled)()
+			}
 			tc.adminSecret, tc.certificateSecret, tc.adc = tc.customizeInput(tc.adminSecret, tc.certificateSecret, tc.adc)
 			if tc.adminSecret != nil {
 				err := kclient.Create(context.Background(), tc.adminSecret)
@@ -388,6 +735,30 @@ func TestUpdateExistingAKODeploymentConfig(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name:              "akodeployment should not update AKO namespace",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			old:               staticADC.DeepCopy(),
+			new:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.AKONamespace = "custom-ns"
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "akodeployment should allow defaulting AKO namespace",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			old:               staticADC.DeepCopy(),
+			new:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				adc.Spec.AKONamespace = AviNamespace
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
 		{
 			name:              "akodeployment should not update cluster selector",
 			adminSecret:       staticAdminSecret.DeepCopy(),
@@ -435,6 +806,42 @@ func TestUpdateExistingAKODeploymentConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name:              "akodeployment should not update overrides to invalid service engine group",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			old:               staticADC.DeepCopy(),
+			new:               staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				rejectServiceEngineGroup("Override-SEG")
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ServiceEngineGroup: "Override-SEG",
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: true,
+		},
+		{
+			name:              "akodeployment should not validate unchanged overrides again",
+			adminSecret:       staticAdminSecret.DeepCopy(),
+			certificateSecret: staticCASecret.DeepCopy(),
+			old: func() *AKODeploymentConfig {
+				adc := staticADC.DeepCopy()
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ServiceEngineGroup: "Override-SEG",
+				}}
+				return adc
+			}(),
+			new: staticADC.DeepCopy(),
+			customizeInput: func(adminSecret, certificateSecret *corev1.Secret, adc *AKODeploymentConfig) (*corev1.Secret, *corev1.Secret, *AKODeploymentConfig) {
+				rejectServiceEngineGroup("Override-SEG")
+				adc.Spec.Overrides = []AKODeploymentConfigOverride{{
+					ServiceEngineGroup: "Override-SEG",
+				}}
+				return adminSecret, certificateSecret, adc
+			},
+			expectErr: false,
+		},
 		{
 			name:              "akodeployment should not update to invalid cloud, seg and data plane",
 			adminSecret:       staticAdminSecret.DeepCopy(),
@@ -545,3 +952,65 @@ func TestDeleteAKODeploymentConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateController(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name       string
+		controller string
+		expectErr  bool
+	}{
+		{
+			name:       "valid ipv4 address should pass validation",
+			controller: "10.180.112.10",
+			expectErr:  false,
+		},
+		{
+			name:       "valid ipv6 address should pass validation",
+			controller: "fd00:10:180::10",
+			expectErr:  false,
+		},
+		{
+			name:       "valid fqdn should pass validation",
+			controller: "avi-controller.example.com",
+			expectErr:  false,
+		},
+		{
+			name:       "valid hostname without dots should pass validation",
+			controller: "avi-controller",
+			expectErr:  false,
+		},
+		{
+			name:       "should throw error if controller is empty",
+			controller: "",
+			expectErr:  true,
+		},
+		{
+			name:       "should throw error if controller contains space",
+			controller: "avi controller.example.com",
+			expectErr:  true,
+		},
+		{
+			name:       "should throw error if controller has leading or trailing space",
+			controller: " 10.180.112.10 ",
+			expectErr:  true,
+		},
+		{
+			name:       "should throw error if controller contains invalid characters",
+			controller: "avi_controller!.example.com",
+			expectErr:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateController(tc.controller)
+			if !tc.expectErr {
+				g.Expect(err).ShouldNot(HaveOccurred())
+			} else {
+				g.Expect(err).Should(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxAVIObjectNameLength is the max length of an AVI object name
+const MaxAVIObjectNameLength = 255
+
+// SanitizeAVIObjectName makes name a valid AVI object name, which must match
+// ^[a-zA-Z0-9_-]{1,255}$, e.g. for names built from cluster names containing
+// dots. Every illegal character is replaced with "-" and the result is
+// truncated to MaxAVIObjectNameLength, so the same name is always sanitized
+// the same way. An empty name can't be sanitized and returns an error.
+func SanitizeAVIObjectName(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("AVI object name should not be empty")
+	}
+	sanitized := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+	if len(sanitized) > MaxAVIObjectNameLength {
+		sanitized = sanitized[:MaxAVIObjectNameLength]
+	}
+	return sanitized, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSanitizeAVIObjectName(t *testing.T) {
+	aviObjectName := regexp.MustCompile(`^[a-zA-Z0-9_-]{1,255}$`)
+
+	cases := []struct {
+		name      string
+		input     string
+		expect    string
+		expectErr bool
+	}{
+		{
+			name:   "valid name",
+			input:  "default-test_cluster-01",
+			expect: "default-test_cluster-01",
+		},
+		{
+			name:   "name with dots",
+			input:  "test.cluster.local",
+			expect: "test-cluster-local",
+		},
+		{
+			name:   "name with slashes",
+			input:  "default/test-cluster",
+			expect: "default-test-cluster",
+		},
+		{
+			name:   "name with unicode",
+			input:  "tëst-集群",
+			expect: "t-st---",
+		},
+		{
+			name:   "name of 300 characters",
+			input:  strings.Repeat("a", 300),
+			expect: strings.Repeat("a", MaxAVIObjectNameLength),
+		},
+		{
+			name:   "long name with dots",
+			input:  strings.Repeat("a.", 150),
+			expect: strings.Repeat("a-", 127) + "a",
+		},
+		{
+			name:      "empty name",
+			input:     "",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			sanitized, err := SanitizeAVIObjectName(tc.input)
+			if tc.expectErr {
+				g.Expect(err).Should(HaveOccurred())
+				return
+			}
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(sanitized).To(Equal(tc.expect))
+			g.Expect(aviObjectName.MatchString(sanitized)).To(BeTrue())
+			// sanitizing is deterministic and idempotent
+			g.Expect(SanitizeAVIObjectName(tc.input)).To(Equal(sanitized))
+			g.Expect(SanitizeAVIObjectName(sanitized)).To(Equal(sanitized))
+		})
+	}
+}
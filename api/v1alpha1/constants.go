@@ -32,10 +32,47 @@ const (
 	AkoDeploymentConfigKind          = "AKODeploymentConfig"
 	AkoDeploymentConfigVersion       = "networking.tanzu.vmware.com/v1alpha1"
 	AkoStatefulSetName               = "ako"
+	AkoConfigMapName                 = "avi-k8s-config"
 	AkoClusterBootstrapRefNamePrefix = "load-balancer-and-ingress-service.tanzu.vmware.com"
 	AkoPackageInstallName            = "load-balancer-and-ingress-service"
 	AkoPreferredIPAnnotation         = "ako.vmware.com/load-balancer-ip"
 
+	// AKODeploymentConfigClusterLabel is set on a Cluster by users to the name of
+	// the AKODeploymentConfig to use for it, which wins over the cluster
+	// selectors. Unlike the AviClusterLabel, AKO Operator never sets it.
+	AKODeploymentConfigClusterLabel = "operator.ako.vmware.com/akodeploymentconfig"
+	// ClusterClassDefaultADCAnnotation is set on a ClusterClass and references the
+	// AKODeploymentConfig applied to Clusters created from this ClusterClass
+	ClusterClassDefaultADCAnnotation = "operator.ako.vmware.com/default-akodeploymentconfig"
+	// AKOAddonTemplateVersionAnnotation is set on the AKO add-on secret to the hash of
+	// the rendered AKO values, it's used to skip re-applying unchanged values
+	AKOAddonTemplateVersionAnnotation = "operator.ako.vmware.com/template-version"
+	// AKOAddonTemplateSchemaVersionAnnotation is set on the AKO add-on secret to
+	// the version of the schema of the rendered AKO values, an older version is
+	// migrated before the new values are applied
+	AKOAddonTemplateSchemaVersionAnnotation = "operator.ako.vmware.com/template-schema-version"
+	// AKOAddonTemplateSchemaVersion is the version of the schema of the AKO values
+	// rendered by this operator
+	AKOAddonTemplateSchemaVersion = "v3"
+	// AKOConfigChecksumAnnotation is set on the AKO StatefulSet and its pod template
+	// in the workload cluster to the checksum of the AKO ConfigMap, changing it on
+	// the pod template restarts AKO with the new configuration
+	AKOConfigChecksumAnnotation = "operator.ako.vmware.com/config-checksum"
+	// LastChangeByAnnotation is set on a AKODeploymentConfig to who changed its
+	// spec last time, when and the diff of the change
+	LastChangeByAnnotation = "operator.ako.vmware.com/last-change-by"
+	// LastReconciledSpecAnnotation is set on a AKODeploymentConfig to the JSON
+	// of its spec the last time it was reconciled, it's used to log the spec
+	// changes at the start of the next reconcile
+	LastReconciledSpecAnnotation = "operator.ako.vmware.com/last-reconciled-spec"
+	// MachineIPAnnotation is set on a Machine by providers which don't report
+	// the Machine addresses in its status
+	MachineIPAnnotation = "cluster.x-k8s.io/machine-ip"
+	// RecordedMachineIPAnnotation is set by AKO Operator on a running Machine to
+	// the IP backing the control plane endpoint, so the IP is still known when
+	// the Machine addresses are gone at deletion
+	RecordedMachineIPAnnotation = "operator.ako.vmware.com/machine-ip"
+
 	AviClusterLabel                                              = "networking.tkg.tanzu.vmware.com/avi"
 	AviClusterDeleteConfigLabel                                  = "networking.tkg.tanzu.vmware.com/avi-config-delete"
 	AviClusterSecretType                                         = "avi.cluster.x-k8s.io/secret"
@@ -44,10 +81,40 @@ const (
 	AviCAName                                                    = "avi-controller-ca"
 	AviCertificateKey                                            = "certificateAuthorityData"
 	AviResourceCleanupReason                                     = "AviResourceCleanup"
+	AviIPPoolNearlyExhaustedReason                               = "IPPoolNearlyExhausted"
 	AviResourceCleanupSucceededCondition clusterv1.ConditionType = "AviResourceCleanupSucceeded"
 	AviUserCleanupSucceededCondition     clusterv1.ConditionType = "AviUserCleanupSucceeded"
 	PreTerminateAnnotation                                       = clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/avi-cleanup"
 
+	ControllerVersionIncompatibleCondition clusterv1.ConditionType = "ControllerVersionIncompatible"
+	ControllerVersionIncompatibleReason                            = "ControllerVersionBelowMinimum"
+
+	TenantValidatedCondition        clusterv1.ConditionType = "TenantValidated"
+	AviTenantNotFoundReason                                 = "TenantNotFound"
+	AviTenantValidationFailedReason                         = "TenantValidationFailed"
+
+	AKOHealthyCondition             clusterv1.ConditionType = "AKOHealthy"
+	AviVirtualServiceDownReason                             = "VirtualServiceDown"
+	AviVirtualServiceNotFoundReason                         = "VirtualServiceNotFound"
+	AviHealthCheckFailedReason                              = "AviHealthCheckFailed"
+
+	AKOAvailableCondition clusterv1.ConditionType = "AKOAvailable"
+	AKOUnavailableReason                          = "AKOUnavailable"
+
+	AKODeployedCondition      clusterv1.ConditionType = "AKODeployed"
+	AKODeploymentFailedReason                         = "AKODeploymentFailed"
+
+	AviCABundleKey = "ca.crt"
+
+	AkoPodDisruptionBudgetName = "ako"
+
+	// MutationPreviewAuditAnnotation is the audit annotation of the mutating
+	// webhook responses previewing the JSON merge patch of the mutation in
+	// dry-run mode
+	MutationPreviewAuditAnnotation = "mutation-preview"
+
+	PreTerminateHookTimeoutReason = "PreTerminateHookTimeout"
+
 	HAServiceName                      = "control-plane"
 	HAServiceBootstrapClusterFinalizer = "ako-operator.networking.tkg.tanzu.vmware.com/ha"
 	HAServiceAnnotationsKey            = "skipnodeport.ako.vmware.com/enabled"
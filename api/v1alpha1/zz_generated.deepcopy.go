@@ -72,6 +72,32 @@ func (in *AKODeploymentConfigList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AKODeploymentConfigOverride) DeepCopyInto(out *AKODeploymentConfigOverride) {
+	*out = *in
+	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+	if in.DataNetwork != nil {
+		in, out := &in.DataNetwork, &out.DataNetwork
+		*out = new(DataNetwork)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraConfigs != nil {
+		in, out := &in.ExtraConfigs, &out.ExtraConfigs
+		*out = new(ExtraConfigs)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKODeploymentConfigOverride.
+func (in *AKODeploymentConfigOverride) DeepCopy() *AKODeploymentConfigOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(AKODeploymentConfigOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AKODeploymentConfigSpec) DeepCopyInto(out *AKODeploymentConfigSpec) {
 	*out = *in
@@ -91,10 +117,22 @@ func (in *AKODeploymentConfigSpec) DeepCopyInto(out *AKODeploymentConfigSpec) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.AVICABundleRef != nil {
+		in, out := &in.AVICABundleRef, &out.AVICABundleRef
+		*out = new(SecretRef)
+		**out = **in
+	}
 	out.Tenant = in.Tenant
 	in.DataNetwork.DeepCopyInto(&out.DataNetwork)
 	out.ControlPlaneNetwork = in.ControlPlaneNetwork
 	in.ExtraConfigs.DeepCopyInto(&out.ExtraConfigs)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]AKODeploymentConfigOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKODeploymentConfigSpec.
@@ -117,6 +155,23 @@ func (in *AKODeploymentConfigStatus) DeepCopyInto(out *AKODeploymentConfigStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ManagedResources != nil {
+		in, out := &in.ManagedResources, &out.ManagedResources
+		*out = make([]ManagedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectedClusters != nil {
+		in, out := &in.SelectedClusters, &out.SelectedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterStatuses != nil {
+		in, out := &in.ClusterStatuses, &out.ClusterStatuses
+		*out = make([]ClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKODeploymentConfigStatus.
@@ -236,6 +291,28 @@ func (in *AVITenant) DeepCopy() *AVITenant {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneNetwork) DeepCopyInto(out *ControlPlaneNetwork) {
 	*out = *in
@@ -285,6 +362,11 @@ func (in *ExtraConfigs) DeepCopyInto(out *ExtraConfigs) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	if in.EnableEvents != nil {
 		in, out := &in.EnableEvents, &out.EnableEvents
 		*out = new(bool)
@@ -336,6 +418,7 @@ func (in *ExtraConfigs) DeepCopyInto(out *ExtraConfigs) {
 	out.L4Configs = in.L4Configs
 	out.NodePortSelector = in.NodePortSelector
 	in.Rbac.DeepCopyInto(&out.Rbac)
+	out.VIPConfig = in.VIPConfig
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraConfigs.
@@ -363,6 +446,21 @@ func (in *IPPool) DeepCopy() *IPPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedResource) DeepCopyInto(out *ManagedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedResource.
+func (in *ManagedResource) DeepCopy() *ManagedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
 	*out = *in
@@ -453,6 +551,21 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VIPConfig) DeepCopyInto(out *VIPConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VIPConfig.
+func (in *VIPConfig) DeepCopy() *VIPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VIPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VIPNetwork) DeepCopyInto(out *VIPNetwork) {
 	*out = *in
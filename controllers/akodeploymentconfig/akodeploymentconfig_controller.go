@@ -5,21 +5,29 @@ package akodeploymentconfig
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/phases"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/user"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/leaderelection"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/netprovider"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -41,21 +49,41 @@ func (r *AKODeploymentConfigReconciler) SetupWithManager(mgr ctrl.Manager) error
 			&source.Kind{Type: &clusterv1.Cluster{}},
 			handler.EnqueueRequestsFromMapFunc(handlers.AkoDeploymentConfigForCluster(r.Client, r.Log)),
 		).
+		Watches(
+			&source.Kind{Type: &clusterv1.ClusterClass{}},
+			handler.EnqueueRequestsFromMapFunc(handlers.AkoDeploymentConfigForClusterClass(r.Log)),
+		).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(r.secretToAKODeploymentConfig(r.Client, r.Log)),
 		).
-		Complete(r)
+		Complete(leaderelection.Reconciler(r))
 }
 
+// DefaultReconcileDebounceInterval is the default window in which consecutive
+// events for the same AKODeploymentConfig are coalesced into a single reconcile.
+const DefaultReconcileDebounceInterval = 5 * time.Second
+
 type AKODeploymentConfigReconciler struct {
 	client.Client
 	aviClient         aviclient.Client
 	Log               logr.Logger
 	Scheme            *runtime.Scheme
+	Recorder          record.EventRecorder
 	userReconciler    *user.AkoUserReconciler
 	ClusterReconciler *cluster.ClusterReconciler
 	netprovider.UsableNetworkProvider
+
+	// ReconcileDebounceInterval is the minimum interval between two reconciles
+	// of the same AKODeploymentConfig. Events arriving within it are coalesced
+	// into one re-run once the interval elapses. Zero disables debouncing.
+	ReconcileDebounceInterval time.Duration
+	// debounceTimers maps an AKODeploymentConfig key to the time.Time at which
+	// its current debounce window ends.
+	debounceTimers sync.Map
+	// conditionTransitions logs and records how long the conditions of each
+	// AKODeploymentConfig take to transition
+	conditionTransitions metrics.ConditionTransitionTracker
 }
 
 func (r *AKODeploymentConfigReconciler) SetAviClient(client aviclient.Client) {
@@ -68,12 +96,20 @@ func (r *AKODeploymentConfigReconciler) SetAviClient(client aviclient.Client) {
 // +kubebuilder:rbac:groups=networking.tkg.tanzu.vmware.com,resources=akodeploymentconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.tkg.tanzu.vmware.com,resources=akodeploymentconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;list;watch;update;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=ako.vmware.com,resources=aviinfrasettings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=run.tanzu.vmware.com,resources=clusterbootstraps;clusterbootstraps/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusterclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=run.tanzu.vmware.com,resources=tanzukubernetesreleases;tanzukubernetesreleases/status,verbs=get;list;watch
 
 func (r *AKODeploymentConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
-	log := r.Log.WithValues("AKODeploymentConfig", req.NamespacedName)
+	log := r.Log.WithValues("akodeploymentconfig_name", req.NamespacedName.String())
+
+	start := time.Now()
+	skipped := false
+	defer func() {
+		metrics.ObserveReconcileDuration("akodeploymentconfig", start, skipped, reterr)
+	}()
 	res := ctrl.Result{}
 	var err error
 
@@ -82,11 +118,23 @@ func (r *AKODeploymentConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 	if err = r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("AKODeploymentConfig not found, will not reconcile")
+			r.debounceTimers.Delete(req.NamespacedName.String())
+			r.conditionTransitions.Forget(req.NamespacedName.String())
+			skipped = true
 			return res, nil
 		}
 		return res, err
 	}
 
+	// Coalesce rapid updates of a non-deleted resource, deletion is never delayed.
+	if obj.GetDeletionTimestamp().IsZero() {
+		if wait := r.debounce(req.NamespacedName.String()); wait > 0 {
+			log.V(3).Info("AKODeploymentConfig reconciled recently, debouncing", "requeue_after", wait)
+			skipped = true
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
 	// Always Patch when exiting this function so changes to the resource are updated on the API server.
 	patchHelper, err := patch.NewHelper(obj, r.Client)
 	if err != nil {
@@ -94,7 +142,13 @@ func (r *AKODeploymentConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 			obj.GroupVersionKind(), req.NamespacedName)
 	}
 	defer func() {
-		if err := patchHelper.Patch(ctx, obj); err != nil {
+		r.conditionTransitions.Track(log, "akodeploymentconfig", req.NamespacedName.String(), obj.Status.Conditions)
+		// only a successful reconcile observes the current generation
+		patchOpts := []patch.Option{}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := utils.PatchWithRetry(ctx, patchHelper, obj, patchOpts...); err != nil {
 			if reterr == nil {
 				reterr = err
 			}
@@ -114,6 +168,10 @@ func (r *AKODeploymentConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 
 	// Handle non-deleted resources.
 	res, err = r.reconcileNormal(ctx, log, obj)
+	if isAVIRateLimited(err) {
+		log.Info("AVI controller rate limited the requests, backing off", "requeue_after", aviRateLimitRequeueInterval)
+		return ctrl.Result{RequeueAfter: aviRateLimitRequeueInterval}, nil
+	}
 	if err != nil {
 		log.Error(err, "failed to reconcile AKODeploymentConfig")
 		return res, err
@@ -121,11 +179,32 @@ func (r *AKODeploymentConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 	return res, nil
 }
 
+// debounce returns how long the reconcile for key has to be delayed. A zero
+// duration means the caller can reconcile now, in which case a new debounce
+// window is started for key. Requests returned with RequeueAfter are
+// de-duplicated by the workqueue, so any number of events received within the
+// window result in a single re-run.
+func (r *AKODeploymentConfigReconciler) debounce(key string) time.Duration {
+	if r.ReconcileDebounceInterval <= 0 {
+		return 0
+	}
+	now := time.Now()
+	if v, ok := r.debounceTimers.Load(key); ok {
+		if wait := v.(time.Time).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	r.debounceTimers.Store(key, now.Add(r.ReconcileDebounceInterval))
+	return 0
+}
+
 func (r *AKODeploymentConfigReconciler) reconcileNormal(
 	ctx context.Context,
 	log logr.Logger,
 	obj *akoov1alpha1.AKODeploymentConfig,
 ) (ctrl.Result, error) {
+	logSpecDiff(log, obj)
+
 	if !ctrlutil.ContainsFinalizer(obj, akoov1alpha1.AkoDeploymentConfigFinalizer) {
 		log.Info("Add finalizer", "finalizer", akoov1alpha1.AkoDeploymentConfigFinalizer)
 		// The finalizer must be present before proceeding in order to ensure that all avi user account
@@ -134,7 +213,30 @@ func (r *AKODeploymentConfigReconciler) reconcileNormal(
 		ctrlutil.AddFinalizer(obj, akoov1alpha1.AkoDeploymentConfigFinalizer)
 	}
 	return phases.ReconcilePhases(ctx, log, obj,
-		[]phases.ReconcilePhase{r.reconcileAVI, r.reconcileClusters})
+		[]phases.ReconcilePhase{r.reconcileAVI, r.reconcileClusters, r.reconcileDeselectedClusters})
+}
+
+// logSpecDiff logs the diff between the spec of the AKODeploymentConfig and
+// the one recorded in the LastReconciledSpecAnnotation, then records the
+// current spec which is persisted by the patch at the end of the reconcile
+func logSpecDiff(log logr.Logger, obj *akoov1alpha1.AKODeploymentConfig) {
+	spec, err := json.Marshal(obj.Spec)
+	if err != nil {
+		log.Error(err, "Failed to marshal AKODeploymentConfig spec")
+		return
+	}
+	if last, ok := obj.Annotations[akoov1alpha1.LastReconciledSpecAnnotation]; ok {
+		lastSpec := akoov1alpha1.AKODeploymentConfigSpec{}
+		if err := json.Unmarshal([]byte(last), &lastSpec); err != nil {
+			log.Error(err, "Failed to unmarshal last reconciled AKODeploymentConfig spec")
+		} else if diff := cmp.Diff(lastSpec, obj.Spec); diff != "" {
+			log.V(3).Info("AKODeploymentConfig spec changed since last reconcile", "diff", diff)
+		}
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[akoov1alpha1.LastReconciledSpecAnnotation] = string(spec)
 }
 
 func (r *AKODeploymentConfigReconciler) reconcileDelete(
@@ -157,7 +259,8 @@ func (r *AKODeploymentConfigReconciler) reconcileDelete(
 		}
 	}()
 	return phases.ReconcilePhases(ctx, log, obj,
-		[]phases.ReconcilePhase{r.reconcileClustersDelete, r.reconcileAVIDelete})
+		[]phases.ReconcilePhase{r.reconcileClustersDelete, r.reconcileManagedResourcesDelete, r.reconcileAVIDelete,
+			r.reconcileMachinePreTerminateHooksDelete})
 }
 
 func (r *AKODeploymentConfigReconciler) secretToAKODeploymentConfig(c client.Client, log logr.Logger) handler.MapFunc {
@@ -167,14 +270,10 @@ func (r *AKODeploymentConfigReconciler) secretToAKODeploymentConfig(c client.Cli
 		if !ok {
 			log.Error(errors.New("invalid type"),
 				"Expected to receive Cluster resource",
-				"actualType", fmt.Sprintf("%T", o))
+				"actual_type", fmt.Sprintf("%T", o))
 			return nil
 		}
-		logger := log.WithValues("Secret", secret.Namespace+"/"+secret.Name)
-
-		if secret.Name != akoov1alpha1.AviCredentialName && secret.Name != akoov1alpha1.AviCAName {
-			return []reconcile.Request{}
-		}
+		logger := log.WithValues("secret_name", client.ObjectKeyFromObject(secret).String())
 
 		var akoDeploymentConfigs akoov1alpha1.AKODeploymentConfigList
 		if err := c.List(ctx, &akoDeploymentConfigs, []client.ListOption{}...); err != nil {
@@ -182,10 +281,13 @@ func (r *AKODeploymentConfigReconciler) secretToAKODeploymentConfig(c client.Cli
 			return []reconcile.Request{}
 		}
 
+		isAviSecret := secret.Name == akoov1alpha1.AviCredentialName || secret.Name == akoov1alpha1.AviCAName
 		var requests []ctrl.Request
 		for _, akoDeploymentConfig := range akoDeploymentConfigs.Items {
-			if akoDeploymentConfig.Spec.CertificateAuthorityRef.Name == secret.Name &&
-				akoDeploymentConfig.Spec.CertificateAuthorityRef.Namespace == secret.Namespace {
+			// the AKO add-on values are rendered again when the workload
+			// credentials are rotated
+			if isSecretRef(akoDeploymentConfig.Spec.WorkloadCredentialRef, secret) ||
+				(isAviSecret && isSecretRef(akoDeploymentConfig.Spec.CertificateAuthorityRef, secret)) {
 				requests = append(requests, ctrl.Request{
 					NamespacedName: types.NamespacedName{
 						Namespace: akoDeploymentConfig.Namespace,
@@ -200,3 +302,8 @@ func (r *AKODeploymentConfigReconciler) secretToAKODeploymentConfig(c client.Cli
 		return requests
 	}
 }
+
+// isSecretRef checks if ref references the secret
+func isSecretRef(ref *akoov1alpha1.SecretRef, secret *corev1.Secret) bool {
+	return ref != nil && ref.Name == secret.Name && ref.Namespace == secret.Namespace
+}
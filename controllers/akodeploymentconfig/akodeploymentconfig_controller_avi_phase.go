@@ -132,8 +132,11 @@ func (r *AKODeploymentConfigReconciler) reconcileAVI(
 		r.reconcileCloudUsableNetwork,
 		r.reconcileAviInfraSetting,
 		r.reconcileControllerVersion,
+		r.reconcileControllerVersionCompatibility,
+		r.reconcileTenant,
+		r.reconcileIPPoolUtilization,
 		func(ctx context.Context, log logr.Logger, obj *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
-			return phases.ReconcileClustersPhases(ctx, r.Client, log, obj,
+			return phases.ReconcileClustersAVIPhases(ctx, r.Client, log, obj,
 				[]phases.ReconcileClusterPhase{
 					r.userReconciler.ReconcileAviUser,
 				},
@@ -161,7 +164,7 @@ func (r *AKODeploymentConfigReconciler) reconcileAVIDelete(
 	return phases.ReconcilePhases(ctx, log, obj, []phases.ReconcilePhase{
 		r.reconcileAviInfraSettingDelete,
 		func(ctx context.Context, log logr.Logger, obj *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
-			return phases.ReconcileClustersPhases(ctx, r.Client, log, obj,
+			return phases.ReconcileClustersAVIPhases(ctx, r.Client, log, obj,
 				[]phases.ReconcileClusterPhase{
 					r.userReconciler.ReconcileAviUserDelete,
 				},
@@ -183,7 +186,7 @@ func (r *AKODeploymentConfigReconciler) reconcileControllerVersion(
 	log logr.Logger,
 	obj *akoov1alpha1.AKODeploymentConfig,
 ) (ctrl.Result, error) {
-	log = log.WithValues("controllerVersion", obj.Spec.ControllerVersion)
+	log = log.WithValues("controller_version", obj.Spec.ControllerVersion)
 	log.Info("Start reconciling AVI controller version")
 
 	version, err := r.aviClient.GetControllerVersion()
@@ -200,7 +203,8 @@ func (r *AKODeploymentConfigReconciler) reconcileControllerVersion(
 }
 
 // reconcileNetworkSubnets ensures the Datanetwork configuration is in sync with
-// AVI Controller configuration
+// AVI Controller configuration, for the data networks of the spec and of the
+// overrides
 func (r *AKODeploymentConfigReconciler) reconcileNetworkSubnets(
 	ctx context.Context,
 	log logr.Logger,
@@ -215,18 +219,29 @@ func (r *AKODeploymentConfigReconciler) reconcileNetworkSubnets(
 		return res, errors.New("AVI client not initialized")
 	}
 
-	network, err := r.aviClient.NetworkGetByName(obj.Spec.DataNetwork.Name, obj.Spec.CloudName)
+	for _, dataNetwork := range dataNetworks(obj) {
+		if err := r.reconcileNetworkSubnet(log, obj.Spec.CloudName, dataNetwork); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// reconcileNetworkSubnet ensures the configuration of dataNetwork is in sync
+// with AVI Controller configuration
+func (r *AKODeploymentConfigReconciler) reconcileNetworkSubnet(log logr.Logger, cloudName string, dataNetwork akoov1alpha1.DataNetwork) error {
+	network, err := r.aviClient.NetworkGetByName(dataNetwork.Name, cloudName)
 	if err != nil {
-		log.Info("[WARN] Failed to get the Data Network from AVI Controller")
-		return res, nil
+		log.Info("[WARN] Failed to get the Data Network from AVI Controller", "network", dataNetwork.Name)
+		return nil
 	}
 
 	// TODO(fangyuanl): move validation to webhook
 	// We also need to make sure IPPools are not overlapping
-	addr, cidr, err := net.ParseCIDR(obj.Spec.DataNetwork.CIDR)
+	addr, cidr, err := net.ParseCIDR(dataNetwork.CIDR)
 	if err != nil {
-		log.Error(err, "Failed to parse the Data Network CIDR")
-		return res, nil
+		log.Error(err, "Failed to parse the Data Network CIDR", "network", dataNetwork.Name)
+		return nil
 	}
 	ones, _ := cidr.Mask.Size()
 	mask := int32(ones)
@@ -236,21 +251,21 @@ func (r *AKODeploymentConfigReconciler) reconcileNetworkSubnets(
 		addrType = "V6"
 	}
 
-	modified := EnsureAviNetwork(network, addrType, cidr, mask, obj.Spec.DataNetwork.IPPools, log)
+	modified := EnsureAviNetwork(network, addrType, cidr, mask, dataNetwork.IPPools, log)
 
 	if modified {
-		log.V(3).Info("Change detected, updating Network", "network", obj.Spec.DataNetwork.Name)
+		log.V(3).Info("Change detected, updating Network", "network", dataNetwork.Name)
 		_, err := r.aviClient.NetworkUpdate(network)
 		if err != nil {
 			log.Error(err, "Failed to update Network, requeue the request", "network", network)
-			return res, err
+			return err
 		}
 		log.Info("Successfully updated Network", "subnets", network.ConfiguredSubnets)
 	} else {
-		log.Info("No change detected for Network", "network", obj.Spec.DataNetwork.Name)
+		log.Info("No change detected for Network", "network", dataNetwork.Name)
 	}
 
-	return res, nil
+	return nil
 }
 
 func (r *AKODeploymentConfigReconciler) reconcileCloudUsableNetwork(
@@ -268,14 +283,47 @@ func (r *AKODeploymentConfigReconciler) reconcileCloudUsableNetwork(
 		}
 	}
 
-	if err := r.AddUsableNetwork(r.aviClient, obj.Spec.CloudName, obj.Spec.DataNetwork.Name, log); err != nil {
-		log.Error(err, "Failed to add usable network", "network", obj.Spec.DataNetwork.Name)
-		return ctrl.Result{}, err
+	// the data networks and custom VIP networks are rendered in the AKO
+	// vip_network_list, so the VIPs are allocated from them
+	var networkNames []string
+	for _, dataNetwork := range dataNetworks(obj) {
+		networkNames = append(networkNames, dataNetwork.Name)
+	}
+	for _, spec := range obj.EffectiveSpecs() {
+		if vipNetworkName := spec.ExtraConfigs.VIPConfig.VIPNetworkName; vipNetworkName != "" {
+			networkNames = append(networkNames, vipNetworkName)
+		}
+	}
+	added := map[string]bool{}
+	for _, networkName := range networkNames {
+		if added[networkName] {
+			continue
+		}
+		if err := r.AddUsableNetwork(r.aviClient, obj.Spec.CloudName, networkName, log); err != nil {
+			log.Error(err, "Failed to add usable network", "network", networkName)
+			return ctrl.Result{}, err
+		}
+		added[networkName] = true
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// dataNetworks returns the distinct data networks of the AKODeploymentConfig
+// clusters, which are the ones of the spec and of the overrides
+func dataNetworks(obj *akoov1alpha1.AKODeploymentConfig) []akoov1alpha1.DataNetwork {
+	var networks []akoov1alpha1.DataNetwork
+	seen := map[string]bool{}
+	for _, spec := range obj.EffectiveSpecs() {
+		if seen[spec.DataNetwork.Name] {
+			continue
+		}
+		seen[spec.DataNetwork.Name] = true
+		networks = append(networks, spec.DataNetwork)
+	}
+	return networks
+}
+
 func (r *AKODeploymentConfigReconciler) reconcileAviInfraSetting(
 	ctx context.Context,
 	log logr.Logger,
@@ -307,6 +355,9 @@ func (r *AKODeploymentConfigReconciler) reconcileAviInfraSetting(
 	return res, r.Update(ctx, aviInfraSetting)
 }
 
+// createAviInfraSetting renders the AVIInfraSetting of the control plane
+// network from the spec, as it's shared by the control plane services of all
+// the clusters and the control plane network can't be overridden
 func (r *AKODeploymentConfigReconciler) createAviInfraSetting(adc *akoov1alpha1.AKODeploymentConfig) *akov1alpha1.AviInfraSetting {
 	// ShardVSSize describes ingress shared virtual service size, default value is SMALL
 	shardSize := "SMALL"
@@ -0,0 +1,34 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/alb-sdk/go/session"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// aviRateLimitRequeueInterval is how long to wait before reconciling again when
+// the AVI controller rate limits the requests. The AVI session only keeps the
+// status code of failed requests, so there is no Retry-After header to honor.
+const aviRateLimitRequeueInterval = 60 * time.Second
+
+// isAVIRateLimited returns true when err is, or aggregates, a 429 Too Many
+// Requests response of the AVI controller. The reconcile phases aggregate
+// their errors, and kerrors.Aggregate can't be unwrapped by errors.As.
+func isAVIRateLimited(err error) bool {
+	if agg, ok := err.(kerrors.Aggregate); ok {
+		for _, e := range agg.Errors() {
+			if isAVIRateLimited(e) {
+				return true
+			}
+		}
+		return false
+	}
+	aviErr := session.AviError{}
+	return errors.As(err, &aviErr) && aviErr.HttpStatusCode == http.StatusTooManyRequests
+}
@@ -39,6 +39,9 @@ func (r *AKODeploymentConfigReconciler) reconcileClusters(
 		[]phases.ReconcileClusterPhase{
 			r.addClusterFinalizer,
 			r.ClusterReconciler.ReconcileAddonSecret,
+			r.ClusterReconciler.ReconcileAKOConfigChecksum,
+			r.ClusterReconciler.ReconcileAKOPodDisruptionBudget,
+			r.ClusterReconciler.ReconcileAKOReadiness,
 		},
 		[]phases.ReconcileClusterPhase{
 			r.ClusterReconciler.ReconcileAddonSecretDelete,
@@ -72,6 +75,37 @@ func (r *AKODeploymentConfigReconciler) reconcileClustersDelete(
 	)
 }
 
+// reconcileDeselectedClusters removes AKO from the clusters which aren't
+// selected by the AKODeploymentConfig anymore
+// It's a reconcilePhase function
+func (r *AKODeploymentConfigReconciler) reconcileDeselectedClusters(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	r.initCluster(log)
+	// the AVI user of a deselected cluster can't be cleaned up without AVI
+	if r.userReconciler == nil {
+		log.Info("AVI user reconciler is not initialized, skip removing AKO from deselected clusters")
+		return ctrl.Result{}, nil
+	}
+
+	return r.ClusterReconciler.ReconcileDeselectedClusters(ctx, log, obj, r.userReconciler.ReconcileAviUserDisable)
+}
+
+// reconcileManagedResourcesDelete deletes the resources created in the
+// selected clusters when a AKODeploymentConfig is being deleted
+// It's a reconcilePhase function
+func (r *AKODeploymentConfigReconciler) reconcileManagedResourcesDelete(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	r.initCluster(log)
+
+	return r.ClusterReconciler.ReconcileManagedResourcesDelete(ctx, log, obj)
+}
+
 // addClusterFinalizer is a reconcileClusterPhase. It adds the AVI
 // finalizer to a Cluster.
 func (r *AKODeploymentConfigReconciler) addClusterFinalizer(
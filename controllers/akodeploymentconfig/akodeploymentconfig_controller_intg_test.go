@@ -265,6 +265,9 @@ func intgTestAkoDeploymentConfigController() {
 		ctx.AviClient.Tenant.SetGetTenantFunc(func(uuid string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
 			return &models.Tenant{}, nil
 		})
+		ctx.AviClient.Tenant.SetGetByNameTenantFunc(func(name string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
+			return &models.Tenant{}, nil
+		})
 	})
 	AfterEach(func() {
 		ctx.AfterEach()
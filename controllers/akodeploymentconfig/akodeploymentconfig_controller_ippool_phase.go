@@ -0,0 +1,146 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/vmware/alb-sdk/go/models"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+)
+
+const (
+	// IPPoolUtilizationCheckInterval is how often the data network ip pool
+	// utilization is checked
+	IPPoolUtilizationCheckInterval = 15 * time.Minute
+	// IPPoolUtilizationThreshold is the utilization above which an
+	// IPPoolNearlyExhausted warning event is emitted
+	IPPoolUtilizationThreshold = 0.9
+)
+
+// reconcileIPPoolUtilization checks how many addresses of the data network ip
+// pools are in use, and emits a warning event on the AKODeploymentConfig when
+// a pool is nearly exhausted. The check never fails the reconciliation.
+func (r *AKODeploymentConfigReconciler) reconcileIPPoolUtilization(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{RequeueAfter: IPPoolUtilizationCheckInterval}
+
+	if r.aviClient == nil {
+		log.Info("AVI client not initialized, skip checking ip pool utilization")
+		return res, nil
+	}
+
+	for _, dataNetwork := range dataNetworks(obj) {
+		log := log.WithValues("data_network", dataNetwork.Name)
+		log.Info("Start reconciling data network ip pool utilization")
+
+		used, total, err := GetIPPoolUtilization(r.aviClient, dataNetwork)
+		if err != nil {
+			log.Info("[WARN] Failed to get ip pool utilization", "error", err.Error())
+			continue
+		}
+		utilization := float64(used) / float64(total)
+		log.V(3).Info("Data network ip pool utilization", "used", used, "total", total)
+
+		if utilization > IPPoolUtilizationThreshold {
+			r.Recorder.Eventf(obj, corev1.EventTypeWarning, akoov1alpha1.AviIPPoolNearlyExhaustedReason,
+				"%d of %d addresses in data network %s are in use (%.0f%%)",
+				used, total, dataNetwork.Name, utilization*100)
+		}
+	}
+	return res, nil
+}
+
+// GetIPPoolUtilization returns the number of used and total addresses of the
+// data network ip pool. Used addresses are read from the AVI ip address group
+// named after the data network, total addresses are the configured IPPools,
+// or the whole CIDR if no IPPool is configured.
+func GetIPPoolUtilization(aviClient aviclient.Client, dataNetwork akoov1alpha1.DataNetwork) (int64, int64, error) {
+	total, err := ipPoolSize(dataNetwork)
+	if err != nil {
+		return 0, 0, err
+	}
+	if total == 0 {
+		return 0, 0, errors.New("data network ip pool is empty")
+	}
+	group, err := aviClient.IPAddrGroupGetByName(dataNetwork.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ipAddrGroupSize(group), total, nil
+}
+
+// ipPoolSize returns the number of addresses in the data network ip pool
+func ipPoolSize(dataNetwork akoov1alpha1.DataNetwork) (int64, error) {
+	if len(dataNetwork.IPPools) == 0 {
+		_, cidr, err := net.ParseCIDR(dataNetwork.CIDR)
+		if err != nil {
+			return 0, err
+		}
+		ones, bits := cidr.Mask.Size()
+		return prefixSize(ones, bits), nil
+	}
+	var total int64
+	for _, ipPool := range dataNetwork.IPPools {
+		total += rangeSize(ipPool.Start, ipPool.End)
+	}
+	return total, nil
+}
+
+// ipAddrGroupSize returns the number of addresses in an AVI ip address group
+func ipAddrGroupSize(group *models.IPAddrGroup) int64 {
+	if group == nil {
+		return 0
+	}
+	size := int64(len(group.Addrs))
+	for _, r := range group.Ranges {
+		if r.Begin != nil && r.Begin.Addr != nil && r.End != nil && r.End.Addr != nil {
+			size += rangeSize(*r.Begin.Addr, *r.End.Addr)
+		}
+	}
+	for _, p := range group.Prefixes {
+		if p.IPAddr == nil || p.IPAddr.Addr == nil || p.Mask == nil {
+			continue
+		}
+		bits := 32
+		if net.ParseIP(*p.IPAddr.Addr).To4() == nil {
+			bits = 128
+		}
+		size += prefixSize(int(*p.Mask), bits)
+	}
+	return size
+}
+
+// rangeSize returns the number of addresses between start and end, inclusive
+func rangeSize(start, end string) int64 {
+	s, e := net.ParseIP(start), net.ParseIP(end)
+	if s == nil || e == nil {
+		return 0
+	}
+	size := new(big.Int).Sub(new(big.Int).SetBytes(e.To16()), new(big.Int).SetBytes(s.To16()))
+	if size.Sign() < 0 {
+		return 0
+	}
+	return size.Int64() + 1
+}
+
+// prefixSize returns the number of addresses in a prefix, capped to fit in an int64
+func prefixSize(ones, bits int) int64 {
+	if bits-ones >= 62 {
+		return 1 << 62
+	}
+	return 1 << uint(bits-ones)
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileMachinePreTerminateHooksDelete removes the pre-terminate hooks owned
+// by the AKODeploymentConfig when it's being deleted, so its finalizer is only
+// removed once no Machine is blocked by them
+// It's a reconcilePhase function
+func (r *AKODeploymentConfigReconciler) reconcileMachinePreTerminateHooksDelete(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines); err != nil {
+		log.Error(err, "Failed to list Machines")
+		return res, err
+	}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if !ako_operator.IsPreTerminateHookOwner(machine, obj) {
+			continue
+		}
+		patch := client.MergeFrom(machine.DeepCopy())
+		ako_operator.RemovePreTerminateHook(machine)
+		if err := r.Client.Patch(ctx, machine, patch); err != nil {
+			log.Error(err, "Failed to remove the pre-terminate hook", "machine_name", client.ObjectKeyFromObject(machine).String())
+			return res, err
+		}
+		log.Info("Removed the pre-terminate hook", "machine_name", client.ObjectKeyFromObject(machine).String())
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+)
+
+// tenantRecheckInterval is how often a missing AVI tenant is checked again
+const tenantRecheckInterval = 5 * time.Minute
+
+// ErrTenantNotFound is returned when the AVI tenant doesn't exist in the AVI
+// controller
+var ErrTenantNotFound = errors.New("AVI tenant not found")
+
+// reconcileTenant marks the AKODeploymentConfig with the TenantValidated
+// condition, after checking spec.tenant.name exists in the AVI controller
+func (r *AKODeploymentConfigReconciler) reconcileTenant(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	// for avi essential version the default tenant is admin
+	tenant := obj.Spec.Tenant.Name
+	if tenant == "" {
+		tenant = "admin"
+	}
+	log = log.WithValues("tenant", tenant)
+	log.Info("Start validating AVI tenant")
+
+	if r.aviClient == nil {
+		log.Info("AVI client not initialized, skip validating tenant")
+		return ctrl.Result{}, nil
+	}
+
+	err := validateTenantExists(ctx, r.aviClient, tenant)
+	if errors.Is(err, ErrTenantNotFound) {
+		log.Info("[WARN] AVI tenant doesn't exist")
+		conditions.MarkFalse(obj, akoov1alpha1.TenantValidatedCondition, akoov1alpha1.AviTenantNotFoundReason,
+			clusterv1.ConditionSeverityWarning, "%s", err.Error())
+		r.Recorder.Eventf(obj, corev1.EventTypeWarning, akoov1alpha1.AviTenantNotFoundReason,
+			"AVI tenant %s doesn't exist in AVI controller %s", tenant, obj.Spec.Controller)
+		// check again later in case the tenant gets created
+		return ctrl.Result{RequeueAfter: tenantRecheckInterval}, nil
+	}
+	if err != nil {
+		log.Error(err, "Failed to validate AVI tenant")
+		conditions.MarkUnknown(obj, akoov1alpha1.TenantValidatedCondition, akoov1alpha1.AviTenantValidationFailedReason,
+			"%s", err.Error())
+		return ctrl.Result{}, err
+	}
+	conditions.MarkTrue(obj, akoov1alpha1.TenantValidatedCondition)
+	return ctrl.Result{}, nil
+}
+
+// validateTenantExists looks the tenant up by name in the AVI controller, and
+// returns ErrTenantNotFound if it doesn't exist
+func validateTenantExists(ctx context.Context, aviClient aviclient.Client, tenant string) error {
+	if _, err := aviClient.TenantGetByName(tenant); err != nil {
+		if aviclient.IsAviTenantNonExistentError(err) {
+			return errors.Wrapf(ErrTenantNotFound, "tenant %s", tenant)
+		}
+		return errors.Wrapf(err, "failed to get AVI tenant %s", tenant)
+	}
+	return nil
+}
@@ -5,14 +5,35 @@ package akodeploymentconfig_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
 	"github.com/vmware/alb-sdk/go/models"
+	"github.com/vmware/alb-sdk/go/session"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -751,3 +772,552 @@ func unitTestEnsureStaticRanges() {
 		})
 	})
 }
+
+func unitTestReconcileDebounce() {
+	Context("debouncing rapid AKODeploymentConfig updates", func() {
+		var (
+			ctx      context.Context
+			rec      *akodeploymentconfig.AKODeploymentConfigReconciler
+			req      ctrl.Request
+			interval time.Duration
+		)
+		BeforeEach(func() {
+			ctx = context.Background()
+			interval = 500 * time.Millisecond
+			scheme := runtime.NewScheme()
+			Expect(akoov1alpha1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			Expect(corev1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			adc := &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-adc"},
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					// the referenced secret doesn't exist, so every reconcileNormal fails
+					CertificateAuthorityRef: &akoov1alpha1.SecretRef{
+						Name:      akoov1alpha1.AviCAName,
+						Namespace: akoov1alpha1.AviNamespace,
+					},
+				},
+			}
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{
+				Client:                    fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(adc).Build(),
+				Log:                       log.Log,
+				Scheme:                    scheme,
+				ReconcileDebounceInterval: interval,
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: adc.Name}}
+		})
+
+		It("should coalesce 5 rapid updates into at most 2 reconciles", func() {
+			normalRuns := 0
+			for i := 0; i < 5; i++ {
+				res, err := rec.Reconcile(ctx, req)
+				if err != nil {
+					normalRuns++
+					continue
+				}
+				Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(res.RequeueAfter).To(BeNumerically("<=", interval))
+			}
+			Expect(normalRuns).To(Equal(1))
+
+			// the coalesced re-run happens once the debounce window elapses
+			time.Sleep(interval)
+			_, err := rec.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+			normalRuns++
+			Expect(normalRuns).To(BeNumerically("<=", 2))
+		})
+
+		It("should not debounce when the interval is zero", func() {
+			rec.ReconcileDebounceInterval = 0
+			for i := 0; i < 5; i++ {
+				_, err := rec.Reconcile(ctx, req)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+}
+
+func unitTestIPPoolUtilization() {
+	Context("GetIPPoolUtilization", func() {
+		var (
+			server      *httptest.Server
+			aviClient   aviclient.Client
+			adc         *akoov1alpha1.AKODeploymentConfig
+			groupResp   string
+			used, total int64
+			err         error
+		)
+		BeforeEach(func() {
+			groupResp = `{"count": 1, "results": [{"name": "VM-Network", "addrs": [{"addr": "10.0.0.1", "type": "V4"}, {"addr": "10.0.0.2", "type": "V4"}], "ranges": [{"begin": {"addr": "10.0.0.3", "type": "V4"}, "end": {"addr": "10.0.0.9", "type": "V4"}}]}]}`
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch {
+				case req.URL.Path == "/login":
+					_, _ = w.Write([]byte(`{}`))
+				case strings.HasPrefix(req.URL.Path, "/api/ipaddrgroup"):
+					Expect(req.URL.Query().Get("name")).To(Equal("VM-Network"))
+					_, _ = w.Write([]byte(groupResp))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			aviClient, err = aviclient.NewAviClient(&aviclient.AviClientConfig{
+				ServerIP: strings.TrimPrefix(server.URL, "https://"),
+				Username: "admin",
+				Password: "Admin!23",
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+				},
+			}, "20.1.3")
+			Expect(err).ShouldNot(HaveOccurred())
+			adc = &akoov1alpha1.AKODeploymentConfig{
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					DataNetwork: akoov1alpha1.DataNetwork{
+						Name: "VM-Network",
+						CIDR: "10.0.0.0/24",
+						IPPools: []akoov1alpha1.IPPool{
+							{Start: "10.0.0.1", End: "10.0.0.10", Type: "V4"},
+						},
+					},
+				},
+			}
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		JustBeforeEach(func() {
+			used, total, err = akodeploymentconfig.GetIPPoolUtilization(aviClient, adc.Spec.DataNetwork)
+		})
+		When("the ip pools are configured", func() {
+			It("should count used addresses of the ip address group against the ip pools", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(used).To(Equal(int64(9)))
+				Expect(total).To(Equal(int64(10)))
+				Expect(float64(used) / float64(total)).To(BeNumerically("<=", akodeploymentconfig.IPPoolUtilizationThreshold))
+			})
+		})
+		When("no ip pool is configured", func() {
+			BeforeEach(func() {
+				adc.Spec.DataNetwork.IPPools = nil
+				groupResp = `{"count": 1, "results": [{"name": "VM-Network", "prefixes": [{"ip_addr": {"addr": "10.0.0.0", "type": "V4"}, "mask": 25}]}]}`
+			})
+			It("should count used addresses against the whole CIDR", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(used).To(Equal(int64(128)))
+				Expect(total).To(Equal(int64(256)))
+			})
+		})
+		When("the ip pool is nearly exhausted", func() {
+			BeforeEach(func() {
+				adc.Spec.DataNetwork.IPPools = []akoov1alpha1.IPPool{
+					{Start: "10.0.0.1", End: "10.0.0.9", Type: "V4"},
+				}
+			})
+			It("should be above the utilization threshold", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(float64(used) / float64(total)).To(BeNumerically(">", akodeploymentconfig.IPPoolUtilizationThreshold))
+			})
+		})
+		When("the ip address group doesn't exist", func() {
+			BeforeEach(func() {
+				groupResp = `{"count": 0, "results": []}`
+			})
+			It("should return an error", func() {
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+}
+
+func unitTestControllerVersionCompatibility() {
+	Context("CheckControllerVersionCompatibility", func() {
+		var (
+			server          *httptest.Server
+			rec             *akodeploymentconfig.AKODeploymentConfigReconciler
+			expectedVersion string
+			err             error
+		)
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/login":
+					_, _ = w.Write([]byte(`{}`))
+				case "/api/cluster/version":
+					_, _ = w.Write([]byte(`{"Version": "21.1.4-2p3", "build": 9052, "ProductName": "Avi Cloud Controller"}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			aviClient, clientErr := aviclient.NewAviClient(&aviclient.AviClientConfig{
+				ServerIP: strings.TrimPrefix(server.URL, "https://"),
+				Username: "admin",
+				Password: "Admin!23",
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+				},
+			}, "21.1.4")
+			Expect(clientErr).ShouldNot(HaveOccurred())
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{Log: log.Log}
+			rec.SetAviClient(aviClient)
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		JustBeforeEach(func() {
+			err = rec.CheckControllerVersionCompatibility(context.Background(), server.URL, expectedVersion)
+		})
+		When("the controller version is higher than the expected version", func() {
+			BeforeEach(func() {
+				expectedVersion = "20.1.7"
+			})
+			It("should be compatible", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+		When("the controller version equals the expected version", func() {
+			BeforeEach(func() {
+				expectedVersion = "21.1.4"
+			})
+			It("should be compatible", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+		When("the controller version is lower than the expected version", func() {
+			BeforeEach(func() {
+				expectedVersion = "22.1.3"
+			})
+			It("should be incompatible", func() {
+				Expect(errors.Is(err, akodeploymentconfig.ErrControllerVersionIncompatible)).To(BeTrue())
+			})
+		})
+		When("the expected version is invalid", func() {
+			BeforeEach(func() {
+				expectedVersion = "latest"
+			})
+			It("should return an error", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(errors.Is(err, akodeploymentconfig.ErrControllerVersionIncompatible)).To(BeFalse())
+			})
+		})
+	})
+}
+
+func unitTestObservedGeneration() {
+	Context("observing the AKODeploymentConfig generation", func() {
+		var (
+			ctx    context.Context
+			rec    *akodeploymentconfig.AKODeploymentConfigReconciler
+			adc    *akoov1alpha1.AKODeploymentConfig
+			req    ctrl.Request
+			resErr error
+		)
+		BeforeEach(func() {
+			ctx = context.Background()
+			adc = &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-adc",
+					Generation: 5,
+				},
+				Status: akoov1alpha1.AKODeploymentConfigStatus{
+					ObservedGeneration: 4,
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(akoov1alpha1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			Expect(corev1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{
+				Client: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(adc).Build(),
+				Log:    log.Log,
+				Scheme: scheme,
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: adc.Name}}
+			_, resErr = rec.Reconcile(ctx, req)
+		})
+		When("the reconcile succeeds", func() {
+			BeforeEach(func() {
+				// the AKODeploymentConfig finalizer is already removed, nothing
+				// is left to clean up
+				now := metav1.Now()
+				adc.DeletionTimestamp = &now
+				adc.Finalizers = []string{"test-finalizer"}
+			})
+			It("should set the observed generation", func() {
+				Expect(resErr).ShouldNot(HaveOccurred())
+				obj := &akoov1alpha1.AKODeploymentConfig{}
+				Expect(rec.Client.Get(ctx, req.NamespacedName, obj)).To(Succeed())
+				Expect(obj.Status.ObservedGeneration).To(Equal(int64(5)))
+			})
+		})
+		When("the reconcile fails", func() {
+			BeforeEach(func() {
+				// the referenced secret doesn't exist, so reconcileNormal fails
+				adc.Spec.CertificateAuthorityRef = &akoov1alpha1.SecretRef{
+					Name:      akoov1alpha1.AviCAName,
+					Namespace: akoov1alpha1.AviNamespace,
+				}
+			})
+			It("should not set the observed generation", func() {
+				Expect(resErr).Should(HaveOccurred())
+				obj := &akoov1alpha1.AKODeploymentConfig{}
+				Expect(rec.Client.Get(ctx, req.NamespacedName, obj)).To(Succeed())
+				Expect(obj.Status.ObservedGeneration).To(Equal(int64(4)))
+			})
+		})
+	})
+}
+
+func unitTestSpecDiffLogging() {
+	Context("logging the AKODeploymentConfig spec changes", func() {
+		var (
+			ctx  context.Context
+			rec  *akodeploymentconfig.AKODeploymentConfigReconciler
+			adc  *akoov1alpha1.AKODeploymentConfig
+			req  ctrl.Request
+			logs []string
+		)
+		reconcile := func() {
+			logs = nil
+			_, _ = rec.Reconcile(ctx, req)
+		}
+		diffLogs := func() []string {
+			var diffs []string
+			for _, l := range logs {
+				if strings.Contains(l, "spec changed since last reconcile") {
+					diffs = append(diffs, l)
+				}
+			}
+			return diffs
+		}
+		BeforeEach(func() {
+			ctx = context.Background()
+			adc = &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-adc",
+				},
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					CloudName: "test-cloud",
+					// the referenced secret doesn't exist, so the reconcile stops
+					// right after the diff is logged
+					CertificateAuthorityRef: &akoov1alpha1.SecretRef{
+						Name:      akoov1alpha1.AviCAName,
+						Namespace: akoov1alpha1.AviNamespace,
+					},
+				},
+			}
+			scheme := runtime.NewScheme()
+			Expect(akoov1alpha1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			Expect(corev1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{
+				Client: fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(adc).Build(),
+				Log: funcr.New(func(prefix, args string) {
+					logs = append(logs, args)
+				}, funcr.Options{Verbosity: 3}),
+				Scheme: scheme,
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: adc.Name}}
+		})
+		It("should log the spec diff when the spec changes", func() {
+			reconcile()
+			Expect(diffLogs()).To(BeEmpty())
+
+			obj := &akoov1alpha1.AKODeploymentConfig{}
+			Expect(rec.Client.Get(ctx, req.NamespacedName, obj)).To(Succeed())
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.LastReconciledSpecAnnotation))
+			obj.Spec.CloudName = "new-cloud"
+			Expect(rec.Client.Update(ctx, obj)).To(Succeed())
+
+			reconcile()
+			Expect(diffLogs()).To(HaveLen(1))
+			Expect(diffLogs()[0]).To(ContainSubstring("test-cloud"))
+			Expect(diffLogs()[0]).To(ContainSubstring("new-cloud"))
+		})
+		It("should not log any diff when the spec doesn't change", func() {
+			reconcile()
+			reconcile()
+			Expect(diffLogs()).To(BeEmpty())
+		})
+	})
+}
+
+func unitTestAVIRateLimit() {
+	It("should only find the rate limited AVI errors", func() {
+		rateLimited := session.AviError{HttpStatusCode: http.StatusTooManyRequests}
+		Expect(akodeploymentconfig.IsAVIRateLimited(pkgerrors.Wrap(rateLimited, "failed to get cloud"))).To(BeTrue())
+
+		Expect(akodeploymentconfig.IsAVIRateLimited(session.AviError{HttpStatusCode: http.StatusNotFound})).To(BeFalse())
+		Expect(akodeploymentconfig.IsAVIRateLimited(errors.New("boom"))).To(BeFalse())
+		Expect(akodeploymentconfig.IsAVIRateLimited(nil)).To(BeFalse())
+	})
+
+	It("should find the rate limited AVI errors in nested aggregates", func() {
+		rateLimited := session.AviError{HttpStatusCode: http.StatusTooManyRequests}
+		Expect(akodeploymentconfig.IsAVIRateLimited(kerrors.NewAggregate([]error{
+			errors.New("boom"),
+			kerrors.NewAggregate([]error{pkgerrors.Wrap(rateLimited, "failed to get cloud")}),
+		}))).To(BeTrue())
+		Expect(akodeploymentconfig.IsAVIRateLimited(kerrors.NewAggregate([]error{errors.New("boom")}))).To(BeFalse())
+	})
+
+	Context("reconciling while the AVI controller rate limits the requests", func() {
+		var (
+			server *httptest.Server
+			rec    *akodeploymentconfig.AKODeploymentConfigReconciler
+			req    ctrl.Request
+		)
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/login" {
+					_, _ = w.Write([]byte(`{}`))
+					return
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error": "too many requests"}`))
+			}))
+			aviClient, err := aviclient.NewAviClient(&aviclient.AviClientConfig{
+				ServerIP: strings.TrimPrefix(server.URL, "https://"),
+				Username: "admin",
+				Password: "Admin!23",
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+				},
+			}, "21.1.4")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			adc := &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-adc",
+					Finalizers: []string{akoov1alpha1.AkoDeploymentConfigFinalizer},
+				},
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					CloudName: "test-cloud",
+					DataNetwork: akoov1alpha1.DataNetwork{
+						Name: "test-network",
+						CIDR: "10.0.0.0/24",
+					},
+					CertificateAuthorityRef: &akoov1alpha1.SecretRef{
+						Name:      akoov1alpha1.AviCAName,
+						Namespace: akoov1alpha1.AviNamespace,
+					},
+				},
+			}
+			// the CA matches the one of the AVI client, so it's not re-created
+			ca := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      akoov1alpha1.AviCAName,
+					Namespace: akoov1alpha1.AviNamespace,
+				},
+			}
+			scheme := runtime.NewScheme()
+			Expect(akoov1alpha1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			Expect(corev1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			Expect(clusterv1.AddToScheme(scheme)).NotTo(HaveOccurred())
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{
+				Client:   fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(adc, ca).Build(),
+				Log:      log.Log,
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			rec.SetAviClient(aviClient)
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: adc.Name}}
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("should back off instead of returning the error", func() {
+			res, err := rec.Reconcile(context.Background(), req)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(60 * time.Second))
+		})
+	})
+}
+
+func unitTestTenantValidation() {
+	Context("validating the AVI tenant", func() {
+		var (
+			server    *httptest.Server
+			aviClient aviclient.Client
+			rec       *akodeploymentconfig.AKODeploymentConfigReconciler
+			recorder  *record.FakeRecorder
+			adc       *akoov1alpha1.AKODeploymentConfig
+			res       ctrl.Result
+			err       error
+		)
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/login":
+					_, _ = w.Write([]byte(`{}`))
+				case "/api/tenant":
+					if req.URL.Query().Get("name") == "admin" {
+						_, _ = w.Write([]byte(`{"count": 1, "results": [{"name": "admin", "uuid": "tenant-uuid"}]}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"count": 0, "results": []}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			var clientErr error
+			aviClient, clientErr = aviclient.NewAviClient(&aviclient.AviClientConfig{
+				ServerIP: strings.TrimPrefix(server.URL, "https://"),
+				Username: "admin",
+				Password: "Admin!23",
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+				},
+			}, "21.1.4")
+			Expect(clientErr).ShouldNot(HaveOccurred())
+			recorder = record.NewFakeRecorder(10)
+			rec = &akodeploymentconfig.AKODeploymentConfigReconciler{Log: log.Log, Recorder: recorder}
+			rec.SetAviClient(aviClient)
+			adc = &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-adc"},
+			}
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		JustBeforeEach(func() {
+			res, err = akodeploymentconfig.ReconcileTenant(rec, context.Background(), log.Log, adc)
+		})
+		When("the tenant exists", func() {
+			BeforeEach(func() {
+				adc.Spec.Tenant.Name = "admin"
+			})
+			It("should mark the tenant validated", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.RequeueAfter).To(BeZero())
+				Expect(conditions.IsTrue(adc, akoov1alpha1.TenantValidatedCondition)).To(BeTrue())
+				Expect(recorder.Events).To(BeEmpty())
+			})
+		})
+		When("the tenant name is empty", func() {
+			It("should validate the admin tenant", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(conditions.IsTrue(adc, akoov1alpha1.TenantValidatedCondition)).To(BeTrue())
+			})
+		})
+		When("the tenant doesn't exist", func() {
+			BeforeEach(func() {
+				adc.Spec.Tenant.Name = "missing"
+			})
+			It("should not validate the tenant", func() {
+				Expect(errors.Is(akodeploymentconfig.ValidateTenantExists(context.Background(), aviClient, "missing"),
+					akodeploymentconfig.ErrTenantNotFound)).To(BeTrue())
+			})
+			It("should mark the tenant not validated and emit a warning event", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res.RequeueAfter).NotTo(BeZero())
+				Expect(conditions.IsFalse(adc, akoov1alpha1.TenantValidatedCondition)).To(BeTrue())
+				Expect(conditions.GetReason(adc, akoov1alpha1.TenantValidatedCondition)).To(Equal(akoov1alpha1.AviTenantNotFoundReason))
+				Expect(conditions.GetSeverity(adc, akoov1alpha1.TenantValidatedCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+				Expect(recorder.Events).To(Receive(And(
+					ContainSubstring(corev1.EventTypeWarning),
+					ContainSubstring(akoov1alpha1.AviTenantNotFoundReason),
+					ContainSubstring("missing"),
+				)))
+			})
+		})
+	})
+}
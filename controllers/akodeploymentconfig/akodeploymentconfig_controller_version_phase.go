@@ -0,0 +1,126 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+)
+
+// controllerVersionRecheckInterval is how often an incompatible AVI controller
+// version is checked again
+const controllerVersionRecheckInterval = 5 * time.Minute
+
+// ErrControllerVersionIncompatible is returned when the AVI controller version is
+// lower than the required one
+var ErrControllerVersionIncompatible = errors.New("AVI controller version is incompatible")
+
+// reconcileControllerVersionCompatibility marks the AKODeploymentConfig with the
+// ControllerVersionIncompatible condition when the AVI controller is older than
+// spec.aviControllerVersion
+func (r *AKODeploymentConfigReconciler) reconcileControllerVersionCompatibility(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	if obj.Spec.AVIControllerVersion == "" {
+		conditions.Delete(obj, akoov1alpha1.ControllerVersionIncompatibleCondition)
+		return ctrl.Result{}, nil
+	}
+	log = log.WithValues("required_version", obj.Spec.AVIControllerVersion)
+	log.Info("Start checking AVI controller version compatibility")
+
+	err := r.CheckControllerVersionCompatibility(ctx, obj.Spec.Controller, obj.Spec.AVIControllerVersion)
+	if errors.Is(err, ErrControllerVersionIncompatible) {
+		log.Info("[WARN] AVI controller version is incompatible", "reason", err.Error())
+		conditions.Set(obj, &clusterv1.Condition{
+			Type:     akoov1alpha1.ControllerVersionIncompatibleCondition,
+			Status:   "True",
+			Severity: clusterv1.ConditionSeverityWarning,
+			Reason:   akoov1alpha1.ControllerVersionIncompatibleReason,
+			Message:  err.Error(),
+		})
+		// degraded, check again later in case the AVI controller gets upgraded
+		return ctrl.Result{RequeueAfter: controllerVersionRecheckInterval}, nil
+	}
+	if err != nil {
+		log.Error(err, "Failed to check AVI controller version compatibility")
+		return ctrl.Result{}, err
+	}
+	conditions.Delete(obj, akoov1alpha1.ControllerVersionIncompatibleCondition)
+	return ctrl.Result{}, nil
+}
+
+// CheckControllerVersionCompatibility queries the version of the AVI controller at host,
+// and returns ErrControllerVersionIncompatible if it is lower than expectedVersion
+func (r *AKODeploymentConfigReconciler) CheckControllerVersionCompatibility(ctx context.Context, host, expectedVersion string) error {
+	if r.aviClient == nil {
+		return errors.New("AVI client not initialized")
+	}
+	version, err := r.aviClient.GetClusterVersion()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get version of AVI controller %s", host)
+	}
+	res, err := compareVersions(version, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if res < 0 {
+		return errors.Wrapf(ErrControllerVersionIncompatible, "AVI controller %s version %s is lower than %s",
+			host, version, expectedVersion)
+	}
+	return nil
+}
+
+// compareVersions compares two dot separated versions, e.g. 22.1.3, and returns -1, 0 or 1
+// if a is lower than, equal to or greater than b. Build suffixes like 22.1.3-9052 are ignored
+func compareVersions(a, b string) (int, error) {
+	parse := func(v string) ([]int, error) {
+		v = strings.SplitN(v, "-", 2)[0]
+		var parts []int
+		for _, p := range strings.Split(v, ".") {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version %q", v)
+			}
+			parts = append(parts, n)
+		}
+		return parts, nil
+	}
+	va, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -39,6 +39,24 @@ func NewReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme) *Cl
 		Log:             log,
 		Scheme:          scheme,
 		GetRemoteClient: remote.NewClusterClient,
+
+		AKOReadyTimeout:      DefaultAKOReadyTimeout,
+		AKOReadyPollInterval: DefaultAKOReadyPollInterval,
+		RequeueInterval:      DefaultRequeueInterval,
+		TemplateMigrations:   DefaultTemplateMigrations(),
+	}
+}
+
+// NewClusterClientGetter returns a ClusterClientGetter which reads the workload
+// cluster kubeconfig Secrets from kubeconfigNamespace, or from the namespace of
+// each Cluster when it's empty, which is the Cluster API convention
+func NewClusterClientGetter(kubeconfigNamespace string) remote.ClusterClientGetter {
+	if kubeconfigNamespace == "" {
+		return remote.NewClusterClient
+	}
+	return func(ctx context.Context, sourceName string, c client.Client, cluster client.ObjectKey) (client.Client, error) {
+		cluster.Namespace = kubeconfigNamespace
+		return remote.NewClusterClient(ctx, sourceName, c, cluster)
 	}
 }
 
@@ -52,6 +70,20 @@ type ClusterReconciler struct {
 	Log             logr.Logger
 	Scheme          *runtime.Scheme
 	GetRemoteClient remote.ClusterClientGetter
+
+	// AKOReadyTimeout is how long AKO has to become available after it's
+	// deployed before ReconcileAKOReadiness reports it unavailable with a warning
+	AKOReadyTimeout time.Duration
+	// AKOReadyPollInterval is how often ReconcileAKOReadiness checks again
+	// whether AKO is available during the AKOReadyTimeout
+	AKOReadyPollInterval time.Duration
+	// RequeueInterval is how long to wait before reconciling again a Cluster
+	// where AKO isn't deployed or available yet
+	RequeueInterval time.Duration
+	// TemplateMigrations maps each AKO add-on template schema version to the
+	// migration of the workload cluster to the next version, which runs before
+	// the add-on secret of the next version is applied
+	TemplateMigrations map[string]TemplateMigration
 }
 
 // ReconcileDelete removes the finalizer on Cluster once AKO finishes its
@@ -157,7 +189,11 @@ func (r *ClusterReconciler) cleanup(
 		log.Info("Updated `deleteConfig` field to true in AKO Addon Data Values, starting ako clean up")
 	}
 
-	cleanupFinished, err := ako.CleanupFinished(ctx, remoteClient, log)
+	akoNamespace := values.LoadBalancerAndIngressService.Namespace
+	if akoNamespace == "" {
+		akoNamespace = akoov1alpha1.AviNamespace
+	}
+	cleanupFinished, err := ako.CleanupFinished(ctx, remoteClient, akoNamespace, log)
 	if err != nil {
 		log.Error(err, "Failed to retrieve AKO cleanup status")
 		return false, err
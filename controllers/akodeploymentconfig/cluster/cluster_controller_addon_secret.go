@@ -5,6 +5,7 @@ package cluster
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"reflect"
@@ -14,6 +15,7 @@ import (
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako"
 	akoo "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,7 +35,7 @@ func (r *ClusterReconciler) ReconcileAddonSecret(
 ) (ctrl.Result, error) {
 	log.Info("Starts reconciling add on secret")
 	res := ctrl.Result{}
-	aviSecret, err := r.getClusterAviUserSecret(cluster, ctx)
+	aviSecret, err := r.getClusterAviUserSecret(ctx, cluster, obj)
 	if err != nil {
 		log.Info("Failed to get cluster avi user secret, requeue")
 		return res, err
@@ -57,6 +59,28 @@ func (r *ClusterReconciler) ReconcileAddonSecret(
 		}
 	}
 
+	// make sure AKO's namespace exists in the workload cluster before
+	// rendering the AKO add-on values
+	remoteClient, err := r.GetRemoteClient(ctx, akoov1alpha1.AKODeploymentConfigControllerName, r.Client, client.ObjectKey{
+		Name:      cluster.Name,
+		Namespace: cluster.Namespace,
+	})
+	if err != nil {
+		log.Info("Failed to create remote client for cluster, requeue the request")
+		return res, err
+	}
+	akoNamespace := obj.GetAKONamespace()
+	if err := EnsureNamespace(ctx, remoteClient, akoNamespace); err != nil {
+		log.Error(err, "Failed to ensure namespace in cluster, requeue", "namespace", akoNamespace)
+		return res, err
+	}
+	if obj.Spec.AVICABundleRef != nil {
+		if aviSecret, err = AppendAVICABundle(ctx, r.Client, obj, aviSecret); err != nil {
+			log.Error(err, "Failed to get AVI CA bundle secret, requeue")
+			return res, err
+		}
+	}
+
 	newAddonSecret, err := r.createAKOAddonSecret(cluster, obj, aviSecret)
 	if err != nil {
 		log.Info("Failed to convert AKO Deployment Config to add-on secret, requeue the request")
@@ -69,15 +93,33 @@ func (r *ClusterReconciler) ReconcileAddonSecret(
 	}, secret); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("AKO add on secret doesn't exist, start creating it")
-			return res, r.Create(ctx, newAddonSecret)
+			if err := r.Create(ctx, newAddonSecret); err != nil {
+				return res, err
+			}
+			return res, nil
 		}
 		log.Error(err, "Failed to get AKO Deployment Secret, requeue")
 		return res, err
 	}
-	secret = newAddonSecret.DeepCopy()
-	if err := r.Update(ctx, secret); err != nil {
-		log.Error(err, "Failed to update ako add on secret, requeue")
-		return res, err
+	// the applied template version is tracked per cluster on the add-on secret,
+	// the values are compared too so the edits of the secret are reverted
+	templateVersion := newAddonSecret.Annotations[akoov1alpha1.AKOAddonTemplateVersionAnnotation]
+	schemaVersion := appliedTemplateSchemaVersion(secret)
+	if secret.Annotations[akoov1alpha1.AKOAddonTemplateVersionAnnotation] == templateVersion &&
+		schemaVersion == akoov1alpha1.AKOAddonTemplateSchemaVersion &&
+		addonSecretValues(secret) == addonSecretValues(newAddonSecret) {
+		log.V(3).Info("AKO add on secret is up to date, skip applying it", "template_version", templateVersion)
+	} else {
+		if err := r.migrateTemplate(ctx, remoteClient, obj, schemaVersion, akoov1alpha1.AKOAddonTemplateSchemaVersion); err != nil {
+			log.Error(err, "Failed to migrate the AKO add on template, requeue", "from", schemaVersion,
+				"to", akoov1alpha1.AKOAddonTemplateSchemaVersion)
+			return res, err
+		}
+		secret = newAddonSecret.DeepCopy()
+		if err := r.Update(ctx, secret); err != nil {
+			log.Error(err, "Failed to update ako add on secret, requeue")
+			return res, err
+		}
 	}
 
 	// patch cluster bootstrap when it is classy cluster and not in bootstrap cluster
@@ -130,6 +172,46 @@ func (r *ClusterReconciler) ReconcileAddonSecretDelete(
 	return res, nil
 }
 
+// EnsureNamespace creates the namespace through the given client if it
+// doesn't exist yet
+func EnsureNamespace(ctx context.Context, remoteClient client.Client, namespace string) error {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+		},
+	}
+	if err := remoteClient.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// AppendAVICABundle returns a copy of the AVI user secret whose AVI
+// Controller's CA has the CA bundle referenced by the AKODeploymentConfig
+// appended, so AKO trusts both
+func AppendAVICABundle(ctx context.Context, c client.Client, obj *akoov1alpha1.AKODeploymentConfig, aviUserSecret *corev1.Secret) (*corev1.Secret, error) {
+	bundle := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{
+		Name:      obj.Spec.AVICABundleRef.Name,
+		Namespace: obj.Spec.AVICABundleRef.Namespace,
+	}, bundle); err != nil {
+		return nil, err
+	}
+	ca := strings.TrimSpace(string(aviUserSecret.Data[akoov1alpha1.AviCertificateKey]))
+	if extra := strings.TrimSpace(string(bundle.Data[akoov1alpha1.AviCABundleKey])); extra != "" {
+		if ca != "" {
+			ca += "\n"
+		}
+		ca += extra
+	}
+	secret := aviUserSecret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[akoov1alpha1.AviCertificateKey] = []byte(ca)
+	return secret, nil
+}
+
 func (r *ClusterReconciler) aviUserSecretName(cluster *clusterv1.Cluster) string {
 	return cluster.Name + "-avi-credentials"
 }
@@ -152,7 +234,9 @@ func (r *ClusterReconciler) createAKOAddonSecret(cluster *clusterv1.Cluster, obj
 			Name:      r.akoAddonSecretName(cluster),
 			Namespace: cluster.Namespace,
 			Annotations: map[string]string{
-				akoov1alpha1.TKGAddonAnnotationKey: "networking/load-balancer-and-ingress-service",
+				akoov1alpha1.TKGAddonAnnotationKey:                   "networking/load-balancer-and-ingress-service",
+				akoov1alpha1.AKOAddonTemplateVersionAnnotation:       templateVersion(secretStringData),
+				akoov1alpha1.AKOAddonTemplateSchemaVersionAnnotation: akoov1alpha1.AKOAddonTemplateSchemaVersion,
 			},
 			Labels: map[string]string{
 				akoov1alpha1.TKGAddOnLabelAddonNameKey:   "load-balancer-and-ingress-service",
@@ -172,10 +256,30 @@ func (r *ClusterReconciler) createAKOAddonSecret(cluster *clusterv1.Cluster, obj
 	return secret, nil
 }
 
+// templateVersion returns the hash of the rendered AKO add-on values
+func templateVersion(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+// addonSecretValues returns the AKO add-on values of the secret, which are in
+// its data once it's read back from the API server
+func addonSecretValues(secret *corev1.Secret) string {
+	if values, ok := secret.Data[akoov1alpha1.TKGAddOnSecretDataKey]; ok {
+		return string(values)
+	}
+	return secret.StringData[akoov1alpha1.TKGAddOnSecretDataKey]
+}
+
 func AkoAddonSecretDataYaml(cluster *clusterv1.Cluster, obj *akoov1alpha1.AKODeploymentConfig, aviUsersecret *corev1.Secret) (string, error) {
+	// render AKO with the fields overridden for the cluster
+	if len(obj.Spec.Overrides) > 0 {
+		obj = obj.DeepCopy()
+		obj.Spec = obj.EffectiveSpec(cluster.Labels)
+	}
 	secret, err := ako.NewValues(obj, cluster.Namespace+"-"+cluster.Name)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to render AKO add-on values of AKODeploymentConfig %s for cluster %s: %w",
+			obj.Name, cluster.Namespace+"/"+cluster.Name, err)
 	}
 
 	//Pass cluster role information to ako
@@ -194,17 +298,48 @@ func AkoAddonSecretDataYaml(cluster *clusterv1.Cluster, obj *akoov1alpha1.AKODep
 	secret.LoadBalancerAndIngressService.Config.Avicredentials.Username = string(aviUsersecret.Data["username"][:])
 	secret.LoadBalancerAndIngressService.Config.Avicredentials.Password = string(aviUsersecret.Data["password"][:])
 	secret.LoadBalancerAndIngressService.Config.Avicredentials.CertificateAuthorityData = string(aviUsersecret.Data[akoov1alpha1.AviCertificateKey][:])
-	return secret.YttYaml(cluster)
+	data, err := secret.YttYaml(cluster)
+	if err != nil {
+		return "", fmt.Errorf("failed to render AKO add-on values of AKODeploymentConfig %s for cluster %s: %w",
+			obj.Name, cluster.Namespace+"/"+cluster.Name, err)
+	}
+	return data, nil
 }
 
-func (r *ClusterReconciler) getClusterAviUserSecret(cluster *clusterv1.Cluster, ctx context.Context) (*corev1.Secret, error) {
-	secret := &corev1.Secret{}
-	if err := r.Get(ctx, client.ObjectKey{
+// getClusterAviUserSecret gets the AVI user credentials AKO uses in the
+// cluster. They come from the WorkloadCredentialRef Secret when the
+// credentials are managed by customers, so rotating it re-renders the AKO
+// add-on values
+func (r *ClusterReconciler) getClusterAviUserSecret(ctx context.Context, cluster *clusterv1.Cluster, obj *akoov1alpha1.AKODeploymentConfig) (*corev1.Secret, error) {
+	key := client.ObjectKey{
 		Name:      r.aviUserSecretName(cluster),
 		Namespace: cluster.Namespace,
-	}, secret); err != nil {
+	}
+	if obj.Spec.WorkloadCredentialRef != nil && cluster.Namespace != akoov1alpha1.TKGSystemNamespace {
+		key = client.ObjectKey{
+			Name:      obj.Spec.WorkloadCredentialRef.Name,
+			Namespace: obj.Spec.WorkloadCredentialRef.Namespace,
+		}
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return secret, err
+	}
+	if _, ok := secret.Data[akoov1alpha1.AviCertificateKey]; ok || obj.Spec.CertificateAuthorityRef == nil {
+		return secret, nil
+	}
+	// the customer managed secret only holds the username and password
+	caSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Name:      obj.Spec.CertificateAuthorityRef.Name,
+		Namespace: obj.Spec.CertificateAuthorityRef.Namespace,
+	}, caSecret); err != nil {
 		return secret, err
 	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[akoov1alpha1.AviCertificateKey] = caSecret.Data[akoov1alpha1.AviCertificateKey]
 	return secret, nil
 }
 
@@ -274,7 +409,7 @@ func (r *ClusterReconciler) patchAkoPackageRefToClusterBootstrap(ctx context.Con
 		}
 	}
 
-	return patchHelper.Patch(ctx, bootstrap.DeepCopy())
+	return utils.PatchWithRetry(ctx, patchHelper, bootstrap.DeepCopy())
 }
 
 // This is not supported at the moment. But good thing is we don't have this scenario at the moment
@@ -299,7 +434,7 @@ func (r *ClusterReconciler) removeAkoPackageRefFromClusterBootstrap(ctx context.
 		}
 	}
 
-	return patchHelper.Patch(ctx, bootstrap.DeepCopy())
+	return utils.PatchWithRetry(ctx, patchHelper, bootstrap.DeepCopy())
 }
 
 func (r *ClusterReconciler) GetAKOPackageRefName(ctx context.Context, log logr.Logger, cb *runv1alpha3.ClusterBootstrap) (string, error) {
@@ -0,0 +1,116 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReconcileAKOConfigChecksum restarts AKO in the workload cluster when its
+// ConfigMap changes, since AKO only reads most of it at startup. The checksum
+// of the ConfigMap is set on the AKO pod template, which rolls the AKO pods.
+// The first checksum seen is only recorded on the StatefulSet, so AKO isn't
+// restarted when the checksum starts being tracked.
+func (r *ClusterReconciler) ReconcileAKOConfigChecksum(
+	ctx context.Context,
+	log logr.Logger,
+	cluster *clusterv1.Cluster,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+	akoNamespace := obj.GetAKONamespace()
+	log = log.WithValues("statefulset_name", types.NamespacedName{Namespace: akoNamespace, Name: akoov1alpha1.AkoStatefulSetName}.String())
+
+	remoteClient, err := r.GetRemoteClient(ctx, akoov1alpha1.AKODeploymentConfigControllerName, r.Client, client.ObjectKey{
+		Name:      cluster.Name,
+		Namespace: cluster.Namespace,
+	})
+	if err != nil {
+		log.Info("Failed to create remote client for cluster, requeue the request")
+		return res, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{
+		Name:      akoov1alpha1.AkoConfigMapName,
+		Namespace: akoNamespace,
+	}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(3).Info("AKO ConfigMap is not deployed yet, skip checking its checksum")
+			return res, nil
+		}
+		log.Error(err, "Failed to get AKO ConfigMap")
+		return res, err
+	}
+	akoStatefulSet := &appsv1.StatefulSet{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{
+		Name:      akoov1alpha1.AkoStatefulSetName,
+		Namespace: akoNamespace,
+	}, akoStatefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(3).Info("AKO StatefulSet is not deployed yet, skip checking the ConfigMap checksum")
+			return res, nil
+		}
+		log.Error(err, "Failed to get AKO StatefulSet")
+		return res, err
+	}
+
+	checksum := configMapChecksum(configMap)
+	applied := akoStatefulSet.Spec.Template.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]
+	if applied == "" {
+		applied = akoStatefulSet.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]
+	}
+	if applied == checksum {
+		return res, nil
+	}
+
+	patch := client.MergeFrom(akoStatefulSet.DeepCopy())
+	if akoStatefulSet.Annotations == nil {
+		akoStatefulSet.Annotations = map[string]string{}
+	}
+	akoStatefulSet.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation] = checksum
+	if applied != "" {
+		if akoStatefulSet.Spec.Template.Annotations == nil {
+			akoStatefulSet.Spec.Template.Annotations = map[string]string{}
+		}
+		akoStatefulSet.Spec.Template.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation] = checksum
+	}
+	if err := remoteClient.Patch(ctx, akoStatefulSet, patch); err != nil {
+		log.Error(err, "Failed to patch the AKO ConfigMap checksum")
+		return res, err
+	}
+	if applied != "" {
+		log.Info("AKO ConfigMap changed, restarting AKO", "checksum", checksum)
+	}
+	return res, nil
+}
+
+// configMapChecksum returns the sha256 of the data of configMap, in the order
+// of its keys
+func configMapChecksum(configMap *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(configMap.Data))
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		// the lengths separate the keys and values so they can't be shifted
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(configMap.Data[k]), configMap.Data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
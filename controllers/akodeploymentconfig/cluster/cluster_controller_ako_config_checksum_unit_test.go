@@ -0,0 +1,132 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestAKOConfigChecksum() {
+	var (
+		ctx                 context.Context
+		remoteClient        client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	getAKOStatefulSet := func() *appsv1.StatefulSet {
+		akoStatefulSet := &appsv1.StatefulSet{}
+		Expect(remoteClient.Get(ctx, client.ObjectKey{
+			Name:      akoov1alpha1.AkoStatefulSetName,
+			Namespace: akoov1alpha1.AviNamespace,
+		}, akoStatefulSet)).To(Succeed())
+		return akoStatefulSet
+	}
+
+	// renderLogLevel updates the AKO ConfigMap the way the AKO package renders
+	// it from the log level of the AKODeploymentConfig
+	renderLogLevel := func() {
+		configMap := &corev1.ConfigMap{}
+		Expect(remoteClient.Get(ctx, client.ObjectKey{
+			Name:      akoov1alpha1.AkoConfigMapName,
+			Namespace: akoov1alpha1.AviNamespace,
+		}, configMap)).To(Succeed())
+		configMap.Data["logLevel"] = akoDeploymentConfig.Spec.ExtraConfigs.Log.LogLevel
+		Expect(remoteClient.Update(ctx, configMap)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		reconciler = cluster.NewReconciler(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				ExtraConfigs: akoov1alpha1.ExtraConfigs{
+					Log: akoov1alpha1.AKOLogConfig{LogLevel: "INFO"},
+				},
+			},
+		}
+	})
+
+	When("AKO is not deployed yet", func() {
+		It("should skip checking the checksum", func() {
+			res, err := reconciler.ReconcileAKOConfigChecksum(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.IsZero()).To(BeTrue())
+		})
+	})
+
+	When("AKO is deployed", func() {
+		BeforeEach(func() {
+			Expect(remoteClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      akoov1alpha1.AkoConfigMapName,
+					Namespace: akoov1alpha1.AviNamespace,
+				},
+				Data: map[string]string{
+					"cloudName": "test-cloud",
+					"logLevel":  "INFO",
+				},
+			})).To(Succeed())
+			Expect(remoteClient.Create(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      akoov1alpha1.AkoStatefulSetName,
+					Namespace: akoov1alpha1.AviNamespace,
+				},
+			})).To(Succeed())
+		})
+
+		It("should record the first checksum without restarting AKO", func() {
+			_, err := reconciler.ReconcileAKOConfigChecksum(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			akoStatefulSet := getAKOStatefulSet()
+			Expect(akoStatefulSet.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]).NotTo(BeEmpty())
+			Expect(akoStatefulSet.Spec.Template.Annotations).NotTo(HaveKey(akoov1alpha1.AKOConfigChecksumAnnotation))
+		})
+
+		It("should restart AKO with a new checksum when an ExtraConfigs field changes", func() {
+			_, err := reconciler.ReconcileAKOConfigChecksum(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			initial := getAKOStatefulSet().Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]
+
+			akoDeploymentConfig.Spec.ExtraConfigs.Log.LogLevel = "DEBUG"
+			renderLogLevel()
+			_, err = reconciler.ReconcileAKOConfigChecksum(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			akoStatefulSet := getAKOStatefulSet()
+			checksum := akoStatefulSet.Spec.Template.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]
+			Expect(checksum).NotTo(BeEmpty())
+			Expect(checksum).NotTo(Equal(initial))
+			Expect(akoStatefulSet.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]).To(Equal(checksum))
+
+			// reconciling again without change should keep the checksum
+			_, err = reconciler.ReconcileAKOConfigChecksum(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(getAKOStatefulSet().Spec.Template.Annotations[akoov1alpha1.AKOConfigChecksumAnnotation]).To(Equal(checksum))
+		})
+	})
+}
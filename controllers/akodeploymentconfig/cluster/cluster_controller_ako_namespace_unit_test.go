@@ -0,0 +1,112 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestAKONamespace() {
+	const akoNamespace = "custom-ns"
+
+	var (
+		ctx                 context.Context
+		mgmtClient          client.Client
+		remoteClient        client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		mgmtClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-avi-credentials",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("Admin!23"),
+				},
+			},
+		).Build()
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      akoov1alpha1.AkoStatefulSetName,
+				Namespace: akoNamespace,
+				UID:       "ako-uid",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ako"}},
+			},
+		}).Build()
+		reconciler = cluster.NewReconciler(mgmtClient, log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+				AKONamespace: akoNamespace,
+			},
+		}
+	})
+
+	It("should create all the AKO objects in the AKO namespace", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoNamespace}, &corev1.Namespace{})).To(Succeed())
+		Expect(remoteClient.Get(ctx, client.ObjectKey{
+			Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+			Namespace: akoNamespace,
+		}, &policyv1.PodDisruptionBudget{})).To(Succeed())
+		Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AviNamespace}, &corev1.Namespace{})).NotTo(Succeed())
+
+		addonSecret := &corev1.Secret{}
+		Expect(mgmtClient.Get(ctx, client.ObjectKey{
+			Name:      "test-cluster-load-balancer-and-ingress-service-addon",
+			Namespace: "default",
+		}, addonSecret)).To(Succeed())
+		values, err := ako.NewValuesFromBytes([]byte(addonSecret.StringData[akoov1alpha1.TKGAddOnSecretDataKey]))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(values.LoadBalancerAndIngressService.Namespace).To(Equal(akoNamespace))
+
+		Expect(akoDeploymentConfig.Status.ManagedResources).To(HaveLen(1))
+		for _, managed := range akoDeploymentConfig.Status.ManagedResources {
+			Expect(managed.Namespace).To(Equal(akoNamespace))
+		}
+	})
+}
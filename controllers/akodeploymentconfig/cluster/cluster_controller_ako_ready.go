@@ -0,0 +1,87 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultAKOReadyTimeout is the default time AKO has to become available
+	// after it's deployed before it's reported unavailable with a warning
+	DefaultAKOReadyTimeout = 15 * time.Second
+	// DefaultAKOReadyPollInterval is the default interval to check again
+	// whether AKO is available in a cluster where it's just been deployed
+	DefaultAKOReadyPollInterval = 5 * time.Second
+	// DefaultRequeueInterval is the default time to wait before checking
+	// again a workload cluster where AKO isn't deployed or available yet
+	DefaultRequeueInterval = 30 * time.Second
+)
+
+// ReconcileAKOReadiness checks that AKO is available in the workload cluster
+// once it's deployed, and reflects it in the AKOAvailable condition of the
+// Cluster. It checks once without blocking the other clusters, and requeues
+// while AKO has no available replica: every AKOReadyPollInterval during the
+// AKOReadyTimeout after the deployment, then every RequeueInterval with a
+// warning.
+func (r *ClusterReconciler) ReconcileAKOReadiness(
+	ctx context.Context,
+	log logr.Logger,
+	cluster *clusterv1.Cluster,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+	akoNamespace := obj.GetAKONamespace()
+	log = log.WithValues("statefulset_name", types.NamespacedName{Namespace: akoNamespace, Name: akoov1alpha1.AkoStatefulSetName}.String())
+
+	remoteClient, err := r.GetRemoteClient(ctx, akoov1alpha1.AKODeploymentConfigControllerName, r.Client, client.ObjectKey{
+		Name:      cluster.Name,
+		Namespace: cluster.Namespace,
+	})
+	if err != nil {
+		log.Info("Failed to create remote client for cluster, requeue the request")
+		return res, err
+	}
+
+	akoStatefulSet := &appsv1.StatefulSet{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{
+		Name:      akoov1alpha1.AkoStatefulSetName,
+		Namespace: akoNamespace,
+	}, akoStatefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(cluster, akoov1alpha1.AKOAvailableCondition, akoov1alpha1.AKOUnavailableReason,
+				clusterv1.ConditionSeverityInfo, "AKO is not deployed yet")
+			log.Info("AKO StatefulSet is not deployed yet, requeue", "after", r.RequeueInterval.String())
+			return ctrl.Result{RequeueAfter: r.RequeueInterval}, nil
+		}
+		log.Error(err, "Failed to get AKO StatefulSet")
+		return res, err
+	}
+
+	if akoStatefulSet.Status.AvailableReplicas >= 1 {
+		conditions.MarkTrue(cluster, akoov1alpha1.AKOAvailableCondition)
+		return res, nil
+	}
+	if time.Since(akoStatefulSet.CreationTimestamp.Time) < r.AKOReadyTimeout {
+		conditions.MarkFalse(cluster, akoov1alpha1.AKOAvailableCondition, akoov1alpha1.AKOUnavailableReason,
+			clusterv1.ConditionSeverityInfo, "AKO has no available replica yet")
+		log.Info("AKO is not available yet, requeue", "after", r.AKOReadyPollInterval.String())
+		return ctrl.Result{RequeueAfter: r.AKOReadyPollInterval}, nil
+	}
+	conditions.MarkFalse(cluster, akoov1alpha1.AKOAvailableCondition, akoov1alpha1.AKOUnavailableReason,
+		clusterv1.ConditionSeverityWarning, "AKO has no available replica after %s", r.AKOReadyTimeout)
+	log.Info("AKO is not available, requeue", "after", r.RequeueInterval.String())
+	return ctrl.Result{RequeueAfter: r.RequeueInterval}, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// akoStatusClient counts the Gets of the AKO StatefulSet
+type akoStatusClient struct {
+	client.Client
+	polls int
+}
+
+func (c *akoStatusClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*appsv1.StatefulSet); ok {
+		c.polls++
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func unitTestAKOReadiness() {
+	var (
+		ctx          context.Context
+		remoteClient *akoStatusClient
+		reconciler   *cluster.ClusterReconciler
+		capicluster  *clusterv1.Cluster
+	)
+
+	akoStatefulSet := func(created time.Time, availableReplicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              akoov1alpha1.AkoStatefulSetName,
+				Namespace:         akoov1alpha1.AviNamespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: appsv1.StatefulSetStatus{AvailableReplicas: availableReplicas},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		remoteClient = &akoStatusClient{}
+		reconciler = cluster.NewReconciler(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+	})
+
+	When("AKO is available", func() {
+		BeforeEach(func() {
+			remoteClient.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				akoStatefulSet(time.Now().Add(-time.Hour), 1)).Build()
+		})
+
+		It("should mark AKO available", func() {
+			res, err := reconciler.ReconcileAKOReadiness(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(remoteClient.polls).To(Equal(1))
+			Expect(conditions.IsTrue(capicluster, akoov1alpha1.AKOAvailableCondition)).To(BeTrue())
+		})
+	})
+
+	When("AKO was just deployed and isn't available yet", func() {
+		BeforeEach(func() {
+			remoteClient.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				akoStatefulSet(time.Now(), 0)).Build()
+		})
+
+		It("should check once and requeue after the poll interval", func() {
+			res, err := reconciler.ReconcileAKOReadiness(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(cluster.DefaultAKOReadyPollInterval))
+			Expect(remoteClient.polls).To(Equal(1))
+			Expect(conditions.IsFalse(capicluster, akoov1alpha1.AKOAvailableCondition)).To(BeTrue())
+			Expect(*conditions.GetSeverity(capicluster, akoov1alpha1.AKOAvailableCondition)).To(Equal(clusterv1.ConditionSeverityInfo))
+		})
+	})
+
+	When("AKO doesn't become available within the timeout", func() {
+		BeforeEach(func() {
+			remoteClient.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				akoStatefulSet(time.Now().Add(-time.Minute), 0)).Build()
+		})
+
+		It("should mark AKO unavailable and requeue", func() {
+			res, err := reconciler.ReconcileAKOReadiness(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(cluster.DefaultRequeueInterval))
+			Expect(remoteClient.polls).To(Equal(1))
+			Expect(conditions.IsFalse(capicluster, akoov1alpha1.AKOAvailableCondition)).To(BeTrue())
+			Expect(conditions.GetReason(capicluster, akoov1alpha1.AKOAvailableCondition)).To(Equal(akoov1alpha1.AKOUnavailableReason))
+			Expect(*conditions.GetSeverity(capicluster, akoov1alpha1.AKOAvailableCondition)).To(Equal(clusterv1.ConditionSeverityWarning))
+		})
+	})
+
+	When("AKO is not deployed yet", func() {
+		BeforeEach(func() {
+			remoteClient.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		})
+
+		It("should requeue without waiting", func() {
+			res, err := reconciler.ReconcileAKOReadiness(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(cluster.DefaultRequeueInterval))
+			Expect(conditions.IsFalse(capicluster, akoov1alpha1.AKOAvailableCondition)).To(BeTrue())
+			Expect(*conditions.GetSeverity(capicluster, akoov1alpha1.AKOAvailableCondition)).To(Equal(clusterv1.ConditionSeverityInfo))
+		})
+	})
+}
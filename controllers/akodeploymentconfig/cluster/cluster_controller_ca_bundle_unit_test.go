@@ -0,0 +1,86 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func unitTestAVICABundle() {
+	var (
+		ctx                 context.Context
+		mgmtClient          client.Client
+		aviUserSecret       *corev1.Secret
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mgmtClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "enterprise-ca",
+				Namespace: "tkg-system",
+			},
+			Data: map[string][]byte{akoov1alpha1.AviCABundleKey: []byte("-----BEGIN CERTIFICATE-----bundle-----END CERTIFICATE-----\n")},
+		}).Build()
+		aviUserSecret = &corev1.Secret{
+			Data: map[string][]byte{
+				"username":                     []byte("admin"),
+				"password":                     []byte("Admin!23"),
+				akoov1alpha1.AviCertificateKey: []byte("-----BEGIN CERTIFICATE-----controller-----END CERTIFICATE-----\n"),
+			},
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+				AVICABundleRef: &akoov1alpha1.SecretRef{
+					Name:      "enterprise-ca",
+					Namespace: "tkg-system",
+				},
+			},
+		}
+	})
+
+	It("should append the CA bundle to the AVI Controller's CA AKO trusts", func() {
+		secret, err := cluster.AppendAVICABundle(ctx, mgmtClient, akoDeploymentConfig, aviUserSecret)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(aviUserSecret.Data[akoov1alpha1.AviCertificateKey])).NotTo(ContainSubstring("bundle"))
+
+		secretYaml, err := cluster.AkoAddonSecretDataYaml(&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+		}, akoDeploymentConfig, secret)
+		Expect(err).ShouldNot(HaveOccurred())
+		values, err := ako.NewValuesFromBytes([]byte(secretYaml))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(values.LoadBalancerAndIngressService.Config.Avicredentials.CertificateAuthorityData).To(Equal(
+			"-----BEGIN CERTIFICATE-----controller-----END CERTIFICATE-----\n" +
+				"-----BEGIN CERTIFICATE-----bundle-----END CERTIFICATE-----"))
+	})
+
+	When("the CA bundle secret doesn't exist in the management cluster", func() {
+		BeforeEach(func() {
+			akoDeploymentConfig.Spec.AVICABundleRef.Name = "non-existent"
+		})
+
+		It("should return error", func() {
+			_, err := cluster.AppendAVICABundle(ctx, mgmtClient, akoDeploymentConfig, aviUserSecret)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/phases"
+	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReconcileDeselectedClusters compares the clusters selected by the
+// AKODeploymentConfig with the ones recorded in its status by the previous
+// reconcile, and removes AKO from the clusters which aren't selected by any
+// AKODeploymentConfig anymore, e.g. when their AVI label is removed. The AVI
+// resources are cleaned up the same way as when the cluster is deleted, then
+// deleteAviUser cleans up the AVI user of the cluster. The selected clusters,
+// and the deselected ones whose cleanup is still in progress, are then
+// recorded in the status.
+// It's a reconcilePhase function
+func (r *ClusterReconciler) ReconcileDeselectedClusters(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+	deleteAviUser phases.ReconcileClusterPhase,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+
+	clusters, err := ako_operator.ListAkoDeploymentConfigSelectClusters(ctx, r.Client, log, obj)
+	if err != nil {
+		log.Error(err, "Fail to list clusters deployed by current AKODeploymentConfig")
+		return res, err
+	}
+	selected := map[string]bool{}
+	for _, cluster := range clusters.Items {
+		selected[cluster.Namespace+"/"+cluster.Name] = true
+	}
+
+	var errs []error
+	for _, key := range obj.Status.SelectedClusters {
+		if selected[key] {
+			continue
+		}
+		clog := log.WithValues("cluster_name", key)
+		keep, clusterRes, err := r.reconcileDeselectedCluster(ctx, clog, key, obj, deleteAviUser)
+		if err != nil {
+			clog.Error(err, "Failed to remove AKO from deselected cluster")
+			errs = append(errs, err)
+		}
+		// keep the cluster to retry on the next reconcile
+		if keep || err != nil {
+			selected[key] = true
+		}
+		res = util.LowestNonZeroResult(res, clusterRes)
+	}
+
+	obj.Status.SelectedClusters = make([]string, 0, len(selected))
+	for key := range selected {
+		obj.Status.SelectedClusters = append(obj.Status.SelectedClusters, key)
+	}
+	sort.Strings(obj.Status.SelectedClusters)
+	return res, kerrors.NewAggregate(errs)
+}
+
+// reconcileDeselectedCluster removes AKO from the cluster unless it's still
+// selected by the AKODeploymentConfig, which happens when the cluster is not
+// ready and so is not listed. A cluster selected by another
+// AKODeploymentConfig is left to it. It returns whether the cluster has to be
+// kept in the status, because it's still selected or its cleanup is in
+// progress.
+func (r *ClusterReconciler) reconcileDeselectedCluster(
+	ctx context.Context,
+	log logr.Logger,
+	key string,
+	obj *akoov1alpha1.AKODeploymentConfig,
+	deleteAviUser phases.ReconcileClusterPhase,
+) (_ bool, _ ctrl.Result, reterr error) {
+	res := ctrl.Result{}
+
+	clusterKey := strings.SplitN(key, "/", 2)
+	if len(clusterKey) != 2 {
+		return false, res, fmt.Errorf("invalid cluster %q, expecting namespace/name", key)
+	}
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterKey[0], Name: clusterKey[1]}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, res, nil
+		}
+		return false, res, err
+	}
+	// AKO is removed with the cluster
+	if !cluster.GetDeletionTimestamp().IsZero() {
+		return false, res, nil
+	}
+
+	adc, err := ako_operator.GetAKODeploymentConfigForCluster(ctx, r.Client, log, cluster)
+	if err != nil {
+		return false, res, err
+	}
+	if adc != nil {
+		return adc.Name == obj.Name, res, nil
+	}
+
+	log.Info("Cluster is not selected by any akodeploymentconfig anymore, removing AKO")
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return false, res, errors.Wrapf(err, "failed to init patch helper for %s %s",
+			cluster.GroupVersionKind(), key)
+	}
+	defer func() {
+		if err := utils.PatchWithRetry(ctx, patchHelper, cluster); err != nil && reterr == nil {
+			reterr = err
+		}
+	}()
+
+	// the AVI resources and the AVI user are cleaned up the same way as when
+	// the cluster is deleted
+	finished, err := r.cleanup(ctx, log, cluster)
+	if err != nil {
+		return false, res, err
+	}
+	if finished {
+		if res, err = deleteAviUser(ctx, log, cluster, obj); err != nil {
+			return false, res, err
+		}
+	}
+	if !finished || !conditions.IsTrue(cluster, akoov1alpha1.AviUserCleanupSucceededCondition) {
+		log.Info("AKO removal is in progress, requeue", "after", requeueAfterForAKODeletion.String())
+		return true, ctrl.Result{RequeueAfter: requeueAfterForAKODeletion}, nil
+	}
+
+	if _, err := r.ReconcileAddonSecretDelete(ctx, log, cluster, obj); err != nil {
+		return false, res, err
+	}
+	if err := r.deleteClusterManagedResources(ctx, log, key, obj); err != nil {
+		return false, res, err
+	}
+	// the cleanup runs again if the cluster is selected and deselected again
+	conditions.Delete(cluster, akoov1alpha1.AviResourceCleanupSucceededCondition)
+	conditions.Delete(cluster, akoov1alpha1.AviUserCleanupSucceededCondition)
+	ako_operator.RemoveClusterLabel(log, cluster)
+	ctrlutil.RemoveFinalizer(cluster, akoov1alpha1.ClusterFinalizer)
+	return false, res, nil
+}
+
+// deleteClusterManagedResources deletes the resources recorded in the
+// AKODeploymentConfig status from the cluster, and drops them from the status
+func (r *ClusterReconciler) deleteClusterManagedResources(
+	ctx context.Context,
+	log logr.Logger,
+	key string,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) error {
+	var remaining []akoov1alpha1.ManagedResource
+	var errs []error
+	for _, managed := range obj.Status.ManagedResources {
+		if managed.Cluster != key {
+			remaining = append(remaining, managed)
+			continue
+		}
+		mlog := log.WithValues("kind", managed.Kind, "resource_name", types.NamespacedName{Namespace: managed.Namespace, Name: managed.Name}.String())
+		if err := r.deleteManagedResource(ctx, managed); err != nil {
+			mlog.Error(err, "Failed to delete managed resource")
+			remaining = append(remaining, managed)
+			errs = append(errs, err)
+			continue
+		}
+		mlog.Info("Managed resource deleted")
+	}
+	obj.Status.ManagedResources = remaining
+	return kerrors.NewAggregate(errs)
+}
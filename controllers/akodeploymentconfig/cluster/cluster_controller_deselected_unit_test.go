@@ -0,0 +1,218 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestDeselectedClusters() {
+	var (
+		ctx                 context.Context
+		mgmtClient          client.Client
+		remoteClient        client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+		addonSecretKey      client.ObjectKey
+		dataValuesKey       client.ObjectKey
+		aviUserDeleted      bool
+	)
+
+	// deleteAviUser fakes the AVI user cleanup of the cluster
+	deleteAviUser := func(_ context.Context, _ logr.Logger, c *clusterv1.Cluster, _ *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+		aviUserDeleted = true
+		conditions.MarkTrue(c, akoov1alpha1.AviUserCleanupSucceededCondition)
+		return ctrl.Result{}, nil
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-cluster",
+				Namespace:  "default",
+				Finalizers: []string{akoov1alpha1.ClusterFinalizer},
+				Labels: map[string]string{
+					"test":                       "true",
+					akoov1alpha1.AviClusterLabel: "test-adc",
+				},
+			},
+		}
+		conditions.MarkTrue(capicluster, clusterv1.ReadyCondition)
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-adc"},
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"test": "true"},
+				},
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+			},
+		}
+		addonSecretKey = client.ObjectKey{
+			Name:      "test-cluster-load-balancer-and-ingress-service-addon",
+			Namespace: "default",
+		}
+		dataValuesKey = client.ObjectKey{
+			Name:      "load-balancer-and-ingress-service-data-values",
+			Namespace: akoov1alpha1.TKGSystemNamespace,
+		}
+		aviUserDeleted = false
+		aviUserSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster-avi-credentials",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("Admin!23"),
+			},
+		}
+		values, err := cluster.AkoAddonSecretDataYaml(capicluster, akoDeploymentConfig, aviUserSecret)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		mgmtScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(akoov1alpha1.AddToScheme(mgmtScheme)).To(Succeed())
+		mgmtClient = fake.NewClientBuilder().WithScheme(mgmtScheme).WithObjects(
+			capicluster.DeepCopy(),
+			akoDeploymentConfig.DeepCopy(),
+			aviUserSecret,
+		).Build()
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dataValuesKey.Name,
+				Namespace: dataValuesKey.Namespace,
+			},
+			Data: map[string][]byte{
+				"values.yaml": []byte(values),
+			},
+		}, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      akoov1alpha1.AkoStatefulSetName,
+				Namespace: akoov1alpha1.AviNamespace,
+				UID:       "ako-uid",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ako"}},
+			},
+		}).Build()
+		reconciler = cluster.NewReconciler(mgmtClient, log.Log, mgmtScheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+
+		// deploy AKO in the cluster
+		_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = reconciler.ReconcileDeselectedClusters(ctx, log.Log, akoDeploymentConfig, deleteAviUser)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(akoDeploymentConfig.Status.SelectedClusters).To(Equal([]string{"default/test-cluster"}))
+		Expect(mgmtClient.Get(ctx, addonSecretKey, &corev1.Secret{})).To(Succeed())
+	})
+
+	updateCluster := func(update func(*clusterv1.Cluster)) {
+		obj := &clusterv1.Cluster{}
+		Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(capicluster), obj)).To(Succeed())
+		update(obj)
+		Expect(mgmtClient.Update(ctx, obj)).To(Succeed())
+	}
+
+	When("the cluster is not selected anymore", func() {
+		BeforeEach(func() {
+			// the avi label set by the operator is kept, only the selector
+			// labels are removed
+			updateCluster(func(c *clusterv1.Cluster) {
+				delete(c.Labels, "test")
+			})
+		})
+
+		It("should wait for the AKO cleanup before removing AKO from the cluster", func() {
+			res, err := reconciler.ReconcileDeselectedClusters(ctx, log.Log, akoDeploymentConfig, deleteAviUser)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+			dataValues := &corev1.Secret{}
+			Expect(remoteClient.Get(ctx, dataValuesKey, dataValues)).To(Succeed())
+			values, err := ako.NewValuesFromBytes(dataValues.Data["values.yaml"])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(values.LoadBalancerAndIngressService.Config.AKOSettings.DeleteConfig).To(Equal("true"))
+			Expect(aviUserDeleted).To(BeFalse())
+			Expect(mgmtClient.Get(ctx, addonSecretKey, &corev1.Secret{})).To(Succeed())
+			Expect(akoDeploymentConfig.Status.SelectedClusters).To(Equal([]string{"default/test-cluster"}))
+
+			// AKO finishes its cleanup
+			ss := &appsv1.StatefulSet{}
+			Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AkoStatefulSetName, Namespace: akoov1alpha1.AviNamespace}, ss)).To(Succeed())
+			ss.Annotations = map[string]string{"AviObjectDeletionStatus": "Done"}
+			Expect(remoteClient.Update(ctx, ss)).To(Succeed())
+
+			_, err = reconciler.ReconcileDeselectedClusters(ctx, log.Log, akoDeploymentConfig, deleteAviUser)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(aviUserDeleted).To(BeTrue())
+
+			err = mgmtClient.Get(ctx, addonSecretKey, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			err = remoteClient.Get(ctx, client.ObjectKey{
+				Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+				Namespace: akoov1alpha1.AviNamespace,
+			}, &policyv1.PodDisruptionBudget{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(akoDeploymentConfig.Status.ManagedResources).To(BeEmpty())
+			Expect(akoDeploymentConfig.Status.SelectedClusters).To(BeEmpty())
+
+			obj := &clusterv1.Cluster{}
+			Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(capicluster), obj)).To(Succeed())
+			Expect(obj.Finalizers).NotTo(ContainElement(akoov1alpha1.ClusterFinalizer))
+			Expect(obj.Labels).NotTo(HaveKey(akoov1alpha1.AviClusterLabel))
+			Expect(conditions.Has(obj, akoov1alpha1.AviResourceCleanupSucceededCondition)).To(BeFalse())
+			Expect(conditions.Has(obj, akoov1alpha1.AviUserCleanupSucceededCondition)).To(BeFalse())
+		})
+	})
+
+	When("the cluster is still selected but not ready", func() {
+		BeforeEach(func() {
+			updateCluster(func(c *clusterv1.Cluster) {
+				conditions.MarkFalse(c, clusterv1.ReadyCondition, "test-reason", clusterv1.ConditionSeverityInfo, "")
+			})
+		})
+
+		It("should keep AKO in the cluster", func() {
+			_, err := reconciler.ReconcileDeselectedClusters(ctx, log.Log, akoDeploymentConfig, deleteAviUser)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(mgmtClient.Get(ctx, addonSecretKey, &corev1.Secret{})).To(Succeed())
+			Expect(akoDeploymentConfig.Status.SelectedClusters).To(Equal([]string{"default/test-cluster"}))
+		})
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// secretKeysClient records the keys of the Secrets it gets
+type secretKeysClient struct {
+	client.Client
+	keys []client.ObjectKey
+}
+
+func (c *secretKeysClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*corev1.Secret); ok {
+		c.keys = append(c.keys, key)
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func unitTestKubeconfigNamespace() {
+	var (
+		ctx        context.Context
+		mgmtClient *secretKeysClient
+		clusterKey client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mgmtClient = &secretKeysClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+		clusterKey = client.ObjectKey{Name: "test-cluster", Namespace: "default"}
+	})
+
+	When("the kubeconfig namespace is configured", func() {
+		It("should look up the kubeconfig Secret in the configured namespace", func() {
+			_, err := cluster.NewClusterClientGetter("clusters")(ctx, "test", mgmtClient, clusterKey)
+			Expect(err).Should(HaveOccurred())
+			Expect(mgmtClient.keys).To(ConsistOf(client.ObjectKey{Name: "test-cluster-kubeconfig", Namespace: "clusters"}))
+		})
+	})
+
+	When("the kubeconfig namespace is not configured", func() {
+		It("should look up the kubeconfig Secret in the cluster namespace", func() {
+			_, err := cluster.NewClusterClientGetter("")(ctx, "test", mgmtClient, clusterKey)
+			Expect(err).Should(HaveOccurred())
+			Expect(mgmtClient.keys).To(ConsistOf(client.ObjectKey{Name: "test-cluster-kubeconfig", Namespace: "default"}))
+		})
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// trackManagedResource records a resource created in the cluster in the
+// AKODeploymentConfig status, so it can be deleted with the
+// AKODeploymentConfig
+func trackManagedResource(obj *akoov1alpha1.AKODeploymentConfig, cluster *clusterv1.Cluster, resource client.Object) error {
+	gvk, err := apiutil.GVKForObject(resource, scheme.Scheme)
+	if err != nil {
+		return err
+	}
+	managed := akoov1alpha1.ManagedResource{
+		Cluster:   cluster.Namespace + "/" + cluster.Name,
+		Group:     gvk.Group,
+		Kind:      gvk.Kind,
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}
+	for _, m := range obj.Status.ManagedResources {
+		if m == managed {
+			return nil
+		}
+	}
+	obj.Status.ManagedResources = append(obj.Status.ManagedResources, managed)
+	return nil
+}
+
+// ReconcileManagedResourcesDelete deletes the resources recorded in the
+// AKODeploymentConfig status from the clusters they were created in. Resources
+// of clusters which are gone are dropped from the status.
+// It's a reconcilePhase function
+func (r *ClusterReconciler) ReconcileManagedResourcesDelete(
+	ctx context.Context,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+
+	var remaining []akoov1alpha1.ManagedResource
+	var errs []error
+	for _, managed := range obj.Status.ManagedResources {
+		mlog := log.WithValues("cluster_name", managed.Cluster, "kind", managed.Kind, "resource_name", types.NamespacedName{Namespace: managed.Namespace, Name: managed.Name}.String())
+		if err := r.deleteManagedResource(ctx, managed); err != nil {
+			mlog.Error(err, "Failed to delete managed resource")
+			remaining = append(remaining, managed)
+			errs = append(errs, err)
+			continue
+		}
+		mlog.Info("Managed resource deleted")
+	}
+	obj.Status.ManagedResources = remaining
+	return res, kerrors.NewAggregate(errs)
+}
+
+func (r *ClusterReconciler) deleteManagedResource(ctx context.Context, managed akoov1alpha1.ManagedResource) error {
+	clusterKey := strings.SplitN(managed.Cluster, "/", 2)
+	if len(clusterKey) != 2 {
+		return fmt.Errorf("invalid cluster %q, expecting namespace/name", managed.Cluster)
+	}
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterKey[0], Name: clusterKey[1]}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the resources are gone with the cluster
+			return nil
+		}
+		return err
+	}
+
+	versions := scheme.Scheme.PrioritizedVersionsForGroup(managed.Group)
+	if len(versions) == 0 {
+		return fmt.Errorf("unknown group %q", managed.Group)
+	}
+	resource, err := scheme.Scheme.New(versions[0].WithKind(managed.Kind))
+	if err != nil {
+		return err
+	}
+	remoteObj, ok := resource.(client.Object)
+	if !ok {
+		return fmt.Errorf("%s is not a client.Object", managed.Kind)
+	}
+	remoteObj.SetNamespace(managed.Namespace)
+	remoteObj.SetName(managed.Name)
+
+	remoteClient, err := r.GetRemoteClient(ctx, akoov1alpha1.AKODeploymentConfigControllerName, r.Client, client.ObjectKeyFromObject(cluster))
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(remoteClient.Delete(ctx, remoteObj))
+}
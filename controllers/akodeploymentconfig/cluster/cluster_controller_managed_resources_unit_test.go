@@ -0,0 +1,117 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestManagedResources() {
+	var (
+		ctx                 context.Context
+		remoteClient        client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		mgmtScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(mgmtScheme)).To(Succeed())
+		mgmtClient := fake.NewClientBuilder().WithScheme(mgmtScheme).WithObjects(
+			capicluster.DeepCopy(),
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-avi-credentials",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("Admin!23"),
+				},
+			},
+		).Build()
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      akoov1alpha1.AkoStatefulSetName,
+				Namespace: akoov1alpha1.AviNamespace,
+				UID:       "ako-uid",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ako"}},
+			},
+		}).Build()
+		reconciler = cluster.NewReconciler(mgmtClient, log.Log, mgmtScheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+			},
+		}
+	})
+
+	It("should delete the resources created in the cluster with the AKODeploymentConfig", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(akoDeploymentConfig.Status.ManagedResources).To(ConsistOf(
+			akoov1alpha1.ManagedResource{
+				Cluster:   "default/test-cluster",
+				Group:     "policy",
+				Kind:      "PodDisruptionBudget",
+				Namespace: akoov1alpha1.AviNamespace,
+				Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+			},
+		))
+
+		// reconciling again should not track the resources twice
+		_, err = reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(akoDeploymentConfig.Status.ManagedResources).To(HaveLen(1))
+
+		_, err = reconciler.ReconcileManagedResourcesDelete(ctx, log.Log, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(akoDeploymentConfig.Status.ManagedResources).To(BeEmpty())
+
+		err = remoteClient.Get(ctx, client.ObjectKey{
+			Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+			Namespace: akoov1alpha1.AviNamespace,
+		}, &policyv1.PodDisruptionBudget{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+}
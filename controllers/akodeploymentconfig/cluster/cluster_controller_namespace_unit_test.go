@@ -0,0 +1,42 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func unitTestEnsureNamespace() {
+	var (
+		ctx          context.Context
+		remoteClient client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	})
+
+	When("the namespace doesn't exist in the workload cluster", func() {
+		It("should create the namespace", func() {
+			Expect(cluster.EnsureNamespace(ctx, remoteClient, akoov1alpha1.AviNamespace)).To(Succeed())
+			ns := &corev1.Namespace{}
+			Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AviNamespace}, ns)).To(Succeed())
+		})
+
+		It("should be idempotent", func() {
+			Expect(cluster.EnsureNamespace(ctx, remoteClient, akoov1alpha1.AviNamespace)).To(Succeed())
+			Expect(cluster.EnsureNamespace(ctx, remoteClient, akoov1alpha1.AviNamespace)).To(Succeed())
+		})
+	})
+}
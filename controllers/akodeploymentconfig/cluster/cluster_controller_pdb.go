@@ -0,0 +1,82 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReconcileAKOPodDisruptionBudget limits the AKO pods in the workload cluster
+// evicted at once during node drain, which would cause load balancer outages.
+// At most one pod can be unavailable, so the usual single AKO replica can
+// still be evicted and node drains don't hang. The PodDisruptionBudget is
+// owned by the AKO StatefulSet so it's garbage collected when AKO is removed.
+func (r *ClusterReconciler) ReconcileAKOPodDisruptionBudget(
+	ctx context.Context,
+	log logr.Logger,
+	cluster *clusterv1.Cluster,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+	akoNamespace := obj.GetAKONamespace()
+	log = log.WithValues("pod_disruption_budget_name", types.NamespacedName{Namespace: akoNamespace, Name: akoov1alpha1.AkoPodDisruptionBudgetName}.String())
+
+	remoteClient, err := r.GetRemoteClient(ctx, akoov1alpha1.AKODeploymentConfigControllerName, r.Client, client.ObjectKey{
+		Name:      cluster.Name,
+		Namespace: cluster.Namespace,
+	})
+	if err != nil {
+		log.Info("Failed to create remote client for cluster, requeue the request")
+		return res, err
+	}
+
+	akoStatefulSet := &appsv1.StatefulSet{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{
+		Name:      akoov1alpha1.AkoStatefulSetName,
+		Namespace: akoNamespace,
+	}, akoStatefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("AKO StatefulSet is not deployed yet, requeue", "after", r.RequeueInterval.String())
+			return ctrl.Result{RequeueAfter: r.RequeueInterval}, nil
+		}
+		log.Error(err, "Failed to get AKO StatefulSet")
+		return res, err
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+			Namespace: akoNamespace,
+		},
+	}
+	op, err := ctrlutil.CreateOrUpdate(ctx, remoteClient, pdb, func() error {
+		maxUnavailable := intstr.FromInt(1)
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+		pdb.Spec.Selector = akoStatefulSet.Spec.Selector
+		return ctrlutil.SetOwnerReference(akoStatefulSet, pdb, scheme.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to reconcile AKO PodDisruptionBudget")
+		return res, err
+	}
+	if op != ctrlutil.OperationResultNone {
+		log.Info("AKO PodDisruptionBudget reconciled", "operation", op)
+	}
+	return res, trackManagedResource(obj, cluster, pdb)
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestAKOPodDisruptionBudget() {
+	var (
+		ctx          context.Context
+		remoteClient client.Client
+		reconciler   *cluster.ClusterReconciler
+		capicluster  *clusterv1.Cluster
+		akoLabels    map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		akoLabels = map[string]string{"app.kubernetes.io/name": "ako"}
+		remoteClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		reconciler = cluster.NewReconciler(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = func(context.Context, string, client.Client, client.ObjectKey) (client.Client, error) {
+			return remoteClient, nil
+		}
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+	})
+
+	When("AKO is not deployed yet", func() {
+		It("should requeue without creating the PodDisruptionBudget", func() {
+			res, err := reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.RequeueAfter).NotTo(BeZero())
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			err = remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AkoPodDisruptionBudgetName, Namespace: akoov1alpha1.AviNamespace}, pdb)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	When("the requeue interval is configured", func() {
+		BeforeEach(func() {
+			reconciler.RequeueInterval = time.Second
+		})
+
+		It("should requeue after the configured interval", func() {
+			res, err := reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{RequeueAfter: time.Second}))
+		})
+	})
+
+	When("AKO is deployed", func() {
+		BeforeEach(func() {
+			Expect(remoteClient.Create(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      akoov1alpha1.AkoStatefulSetName,
+					Namespace: akoov1alpha1.AviNamespace,
+					UID:       "ako-uid",
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: akoLabels},
+				},
+			})).To(Succeed())
+		})
+
+		It("should create the PodDisruptionBudget owned by AKO", func() {
+			res, err := reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.IsZero()).To(BeTrue())
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AkoPodDisruptionBudgetName, Namespace: akoov1alpha1.AviNamespace}, pdb)).To(Succeed())
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(akoLabels))
+			Expect(pdb.OwnerReferences).To(HaveLen(1))
+			Expect(pdb.OwnerReferences[0].Kind).To(Equal("StatefulSet"))
+			Expect(pdb.OwnerReferences[0].Name).To(Equal(akoov1alpha1.AkoStatefulSetName))
+
+			// reconciling again should be a no-op
+			_, err = reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should let the single AKO pod be evicted when the PodDisruptionBudget uses minAvailable", func() {
+			minAvailable := intstr.FromInt(1)
+			Expect(remoteClient.Create(ctx, &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      akoov1alpha1.AkoPodDisruptionBudgetName,
+					Namespace: akoov1alpha1.AviNamespace,
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+			})).To(Succeed())
+
+			_, err := reconciler.ReconcileAKOPodDisruptionBudget(ctx, log.Log, capicluster, &akoov1alpha1.AKODeploymentConfig{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(remoteClient.Get(ctx, client.ObjectKey{Name: akoov1alpha1.AkoPodDisruptionBudgetName, Namespace: akoov1alpha1.AviNamespace}, pdb)).To(Succeed())
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+		})
+	})
+}
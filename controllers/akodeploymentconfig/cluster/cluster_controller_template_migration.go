@@ -0,0 +1,72 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateSchemaVersions are the versions of the schema of the AKO add-on
+// values, from the oldest to the current one
+var templateSchemaVersions = []string{"v1", "v2", akoov1alpha1.AKOAddonTemplateSchemaVersion}
+
+// TemplateMigration migrates the workload cluster from an AKO add-on template
+// schema version to the next one
+type TemplateMigration func(ctx context.Context, remoteClient client.Client, obj *akoov1alpha1.AKODeploymentConfig) error
+
+// DefaultTemplateMigrations returns the migrations of the workload cluster
+// between the AKO add-on template schema versions rendered by this operator.
+// None is registered yet, since the v1 and v2 values never shipped.
+func DefaultTemplateMigrations() map[string]TemplateMigration {
+	return map[string]TemplateMigration{}
+}
+
+// appliedTemplateSchemaVersion returns the template schema version of the
+// applied add-on secret. Secrets rendered before the schema was versioned have
+// no version and are of the oldest one.
+func appliedTemplateSchemaVersion(secret *corev1.Secret) string {
+	if version, ok := secret.Annotations[akoov1alpha1.AKOAddonTemplateSchemaVersionAnnotation]; ok {
+		return version
+	}
+	return templateSchemaVersions[0]
+}
+
+// migrateTemplate runs in order the migrations of the workload cluster from
+// oldVersion up to newVersion of the AKO add-on template schema. Nothing is
+// migrated when oldVersion isn't older than newVersion, or when either version
+// is unknown to this operator, e.g. the secret was rendered by a newer one
+// before a downgrade.
+func (r *ClusterReconciler) migrateTemplate(ctx context.Context, remoteClient client.Client, obj *akoov1alpha1.AKODeploymentConfig, oldVersion, newVersion string) error {
+	from, to := templateSchemaVersionIndex(oldVersion), templateSchemaVersionIndex(newVersion)
+	if from < 0 || to < 0 {
+		return nil
+	}
+	for i := from; i < to; i++ {
+		migrate, ok := r.TemplateMigrations[templateSchemaVersions[i]]
+		if !ok {
+			continue
+		}
+		if err := migrate(ctx, remoteClient, obj); err != nil {
+			return fmt.Errorf("failed to migrate AKO add-on template from %s to %s: %w",
+				templateSchemaVersions[i], templateSchemaVersions[i+1], err)
+		}
+	}
+	return nil
+}
+
+// templateSchemaVersionIndex returns the index of version in
+// templateSchemaVersions, -1 when it's unknown
+func templateSchemaVersionIndex(version string) int {
+	for i, v := range templateSchemaVersions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
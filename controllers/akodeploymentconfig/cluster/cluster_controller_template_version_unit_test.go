@@ -0,0 +1,256 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// countingClient counts the writes going through the client
+type countingClient struct {
+	client.Client
+	creates int
+	updates int
+}
+
+func (c *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.creates++
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *countingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func unitTestAppliedTemplateVersion() {
+	var (
+		ctx                 context.Context
+		fclient             *countingClient
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fclient = &countingClient{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-avi-credentials",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("Admin!23"),
+				},
+			}).Build(),
+		}
+		reconciler = cluster.NewReconciler(fclient, log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = cluster.GetFakeRemoteClient
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+			},
+		}
+	})
+
+	It("should not re-apply the add-on secret when the spec doesn't change", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.creates).To(Equal(1))
+
+		_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.creates).To(Equal(1))
+		Expect(fclient.updates).To(Equal(0))
+	})
+
+	It("should re-apply the add-on secret when its values are edited", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		key := client.ObjectKey{Name: "test-cluster-load-balancer-and-ingress-service-addon", Namespace: "default"}
+		secret := &corev1.Secret{}
+		Expect(fclient.Get(ctx, key, secret)).To(Succeed())
+		rendered := secret.StringData[akoov1alpha1.TKGAddOnSecretDataKey]
+		secret.StringData = nil
+		secret.Data = map[string][]byte{akoov1alpha1.TKGAddOnSecretDataKey: []byte("edited")}
+		Expect(fclient.Client.Update(ctx, secret)).To(Succeed())
+
+		_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.updates).To(Equal(1))
+		Expect(fclient.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.StringData[akoov1alpha1.TKGAddOnSecretDataKey]).To(Equal(rendered))
+	})
+
+	It("should re-apply the add-on secret when the spec changes", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		akoDeploymentConfig.Spec.ServiceEngineGroup = "New-SEG"
+		_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.updates).To(Equal(1))
+	})
+}
+
+func unitTestTemplateMigration() {
+	var (
+		ctx                 context.Context
+		fclient             client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+		migrations          []string
+	)
+
+	// setAppliedSchemaVersion sets the template schema version of the applied
+	// add-on secret, an empty version removes it
+	setAppliedSchemaVersion := func(version string) {
+		secret := &corev1.Secret{}
+		Expect(fclient.Get(ctx, client.ObjectKey{
+			Name:      "test-cluster-load-balancer-and-ingress-service-addon",
+			Namespace: "default",
+		}, secret)).To(Succeed())
+		if version == "" {
+			delete(secret.Annotations, akoov1alpha1.AKOAddonTemplateSchemaVersionAnnotation)
+		} else {
+			secret.Annotations[akoov1alpha1.AKOAddonTemplateSchemaVersionAnnotation] = version
+		}
+		Expect(fclient.Update(ctx, secret)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		migrations = nil
+		fclient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster-avi-credentials",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("Admin!23"),
+			},
+		}).Build()
+		reconciler = cluster.NewReconciler(fclient, log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = cluster.GetFakeRemoteClient
+		reconciler.TemplateMigrations = map[string]cluster.TemplateMigration{
+			"v1": func(context.Context, client.Client, *akoov1alpha1.AKODeploymentConfig) error {
+				migrations = append(migrations, "v1->v2")
+				return nil
+			},
+			"v2": func(context.Context, client.Client, *akoov1alpha1.AKODeploymentConfig) error {
+				migrations = append(migrations, "v2->v3")
+				return nil
+			},
+		}
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+			},
+		}
+
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should not migrate a new add-on secret", func() {
+		Expect(migrations).To(BeEmpty())
+	})
+
+	It("should not migrate the current template version", func() {
+		akoDeploymentConfig.Spec.ServiceEngineGroup = "New-SEG"
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(BeEmpty())
+	})
+
+	It("should migrate a v1 template to v2 then v3", func() {
+		setAppliedSchemaVersion("v1")
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(Equal([]string{"v1->v2", "v2->v3"}))
+	})
+
+	It("should migrate a v2 template to v3", func() {
+		setAppliedSchemaVersion("v2")
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(Equal([]string{"v2->v3"}))
+
+		// the migrated add-on secret is of the current version
+		_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(Equal([]string{"v2->v3"}))
+	})
+
+	It("should migrate an unversioned template from v1", func() {
+		setAppliedSchemaVersion("")
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(Equal([]string{"v1->v2", "v2->v3"}))
+	})
+
+	It("should not migrate an unknown template version", func() {
+		setAppliedSchemaVersion("v4")
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(migrations).To(BeEmpty())
+	})
+
+	It("should not apply the add-on secret when a migration fails", func() {
+		reconciler.TemplateMigrations["v2"] = func(context.Context, client.Client, *akoov1alpha1.AKODeploymentConfig) error {
+			return errors.New("migration failed")
+		}
+		setAppliedSchemaVersion("v2")
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).Should(HaveOccurred())
+
+		secret := &corev1.Secret{}
+		Expect(fclient.Get(ctx, client.ObjectKey{
+			Name:      "test-cluster-load-balancer-and-ingress-service-addon",
+			Namespace: "default",
+		}, secret)).To(Succeed())
+		Expect(secret.Annotations[akoov1alpha1.AKOAddonTemplateSchemaVersionAnnotation]).To(Equal("v2"))
+	})
+}
@@ -187,6 +187,16 @@ func unitTestAKODeploymentYaml() {
 				akoDeploymentConfig.Spec.DataNetwork.CIDR = "10.0.0.0/24"
 			})
 
+			It("should report the AKODeploymentConfig which fails to render", func() {
+				adc := akoDeploymentConfig.DeepCopy()
+				adc.Name = "test-adc"
+				adc.Spec.DataNetwork.CIDR = "test"
+				_, err := cluster.AkoAddonSecretDataYaml(capicluster, adc, aviUserSecret)
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("AKODeploymentConfig test-adc"))
+				Expect(err.Error()).Should(ContainSubstring("test-cluster"))
+			})
+
 			It("should update delete_config in this way", func() {
 				values, err := ako.NewValues(akoDeploymentConfig, "namespace-name")
 				Expect(err).ShouldNot(HaveOccurred())
@@ -244,6 +254,29 @@ func unitTestAKODeploymentYaml() {
 					})
 				})
 			})
+
+			When("an override selects some clusters", func() {
+				BeforeEach(func() {
+					akoDeploymentConfig.Spec.Overrides = []akoov1alpha1.AKODeploymentConfigOverride{{
+						ClusterSelector: metav1.LabelSelector{
+							MatchLabels: map[string]string{"seg": "override"},
+						},
+						ServiceEngineGroup: "Override-SEG",
+					}}
+				})
+
+				It("should render the overridden fields for the selected clusters only", func() {
+					secretData, err := cluster.AkoAddonSecretDataYaml(capicluster, akoDeploymentConfig, aviUserSecret)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(secretData).Should(ContainSubstring("service_engine_group_name: Default-SEG"))
+
+					capicluster.Labels["seg"] = "override"
+					secretData, err = cluster.AkoAddonSecretDataYaml(capicluster, akoDeploymentConfig, aviUserSecret)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(secretData).Should(ContainSubstring("service_engine_group_name: Override-SEG"))
+					Expect(akoDeploymentConfig.Spec.ServiceEngineGroup).To(Equal("Default-SEG"))
+				})
+			})
 		})
 	})
 }
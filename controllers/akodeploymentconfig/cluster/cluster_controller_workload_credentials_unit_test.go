@@ -0,0 +1,119 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestWorkloadCredentials() {
+	var (
+		ctx                 context.Context
+		fclient             client.Client
+		reconciler          *cluster.ClusterReconciler
+		capicluster         *clusterv1.Cluster
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+	)
+
+	getAddonValues := func() string {
+		secret := &corev1.Secret{}
+		Expect(fclient.Get(ctx, client.ObjectKey{
+			Name:      "test-cluster-load-balancer-and-ingress-service-addon",
+			Namespace: "default",
+		}, secret)).To(Succeed())
+		return secret.StringData[akoov1alpha1.TKGAddOnSecretDataKey]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		capicluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		fclient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workload-avi-credentials",
+					Namespace: akoov1alpha1.TKGSystemNamespace,
+				},
+				Data: map[string][]byte{
+					"username": []byte("ako"),
+					"password": []byte("Ako!23"),
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "avi-controller-ca",
+					Namespace: akoov1alpha1.TKGSystemNamespace,
+				},
+				Data: map[string][]byte{
+					akoov1alpha1.AviCertificateKey: []byte("test-ca"),
+				},
+			},
+		).Build()
+		reconciler = cluster.NewReconciler(fclient, log.Log, scheme.Scheme)
+		reconciler.GetRemoteClient = cluster.GetFakeRemoteClient
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				CloudName:          "test-cloud",
+				Controller:         "10.23.122.1",
+				ServiceEngineGroup: "Default-SEG",
+				DataNetwork: akoov1alpha1.DataNetwork{
+					Name: "test-akdc",
+					CIDR: "10.0.0.0/24",
+				},
+				WorkloadCredentialRef: &akoov1alpha1.SecretRef{
+					Name:      "workload-avi-credentials",
+					Namespace: akoov1alpha1.TKGSystemNamespace,
+				},
+				CertificateAuthorityRef: &akoov1alpha1.SecretRef{
+					Name:      "avi-controller-ca",
+					Namespace: akoov1alpha1.TKGSystemNamespace,
+				},
+			},
+		}
+	})
+
+	It("should render the AKO add-on values with the workload credentials", func() {
+		_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		values := getAddonValues()
+		Expect(values).To(ContainSubstring("username: ako"))
+		Expect(values).To(ContainSubstring("password: Ako!23"))
+		Expect(values).To(ContainSubstring("certificate_authority_data: test-ca"))
+	})
+
+	When("the workload credentials are rotated", func() {
+		It("should render the AKO add-on values again on the next reconcile", func() {
+			_, err := reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			source := &corev1.Secret{}
+			Expect(fclient.Get(ctx, client.ObjectKey{
+				Name:      "workload-avi-credentials",
+				Namespace: akoov1alpha1.TKGSystemNamespace,
+			}, source)).To(Succeed())
+			source.Data["password"] = []byte("Rotated!23")
+			Expect(fclient.Update(ctx, source)).To(Succeed())
+
+			_, err = reconciler.ReconcileAddonSecret(ctx, log.Log, capicluster, akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(getAddonValues()).To(ContainSubstring("password: Rotated!23"))
+		})
+	})
+}
@@ -36,4 +36,16 @@ func intgTests() {
 
 func unitTests() {
 	Describe("AKO Deployment Spec generation", unitTestAKODeploymentYaml)
+	Describe("Workload cluster namespace", unitTestEnsureNamespace)
+	Describe("AVI CA bundle", unitTestAVICABundle)
+	Describe("Applied template version", unitTestAppliedTemplateVersion)
+	Describe("Template migration", unitTestTemplateMigration)
+	Describe("AKO PodDisruptionBudget", unitTestAKOPodDisruptionBudget)
+	Describe("AKO ConfigMap checksum", unitTestAKOConfigChecksum)
+	Describe("Managed resources", unitTestManagedResources)
+	Describe("AKO namespace", unitTestAKONamespace)
+	Describe("AKO readiness", unitTestAKOReadiness)
+	Describe("Workload cluster kubeconfig namespace", unitTestKubeconfigNamespace)
+	Describe("Deselected clusters", unitTestDeselectedClusters)
+	Describe("Workload credentials", unitTestWorkloadCredentials)
 }
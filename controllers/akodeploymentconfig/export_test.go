@@ -0,0 +1,11 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package akodeploymentconfig
+
+// exported for the tests of the akodeploymentconfig_test package
+var (
+	IsAVIRateLimited     = isAVIRateLimited
+	ValidateTenantExists = validateTenantExists
+	ReconcileTenant      = (*AKODeploymentConfigReconciler).reconcileTenant
+)
@@ -10,15 +10,20 @@ import (
 	"github.com/go-logr/logr"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
 )
 
 // ReconcilePhase defines a function that reconciles one aspect of
@@ -54,8 +59,9 @@ func ReconcilePhases(
 // AKODeploymentConfig
 type ReconcileClusterPhase func(context.Context, logr.Logger, *clusterv1.Cluster, *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error)
 
-// reconcileClusters reconcile every cluster that matches the
-// AKODeploymentConfig's selector by running through an array of phases
+// ReconcileClustersPhases reconcile every cluster that matches the
+// AKODeploymentConfig's selector by running through an array of phases, and
+// records the state of AKO in each cluster in the AKODeploymentConfig status
 func ReconcileClustersPhases(
 	ctx context.Context,
 	client client.Client,
@@ -63,6 +69,33 @@ func ReconcileClustersPhases(
 	obj *akoov1alpha1.AKODeploymentConfig,
 	normalPhases []ReconcileClusterPhase,
 	deletePhases []ReconcileClusterPhase,
+) (ctrl.Result, error) {
+	return reconcileClustersPhases(ctx, client, log, obj, normalPhases, deletePhases, true)
+}
+
+// ReconcileClustersAVIPhases is ReconcileClustersPhases for the phases
+// conducting AVI related operations, which run in a separate pass. The cluster
+// statuses are only recorded by the pass deploying AKO, so that one pass
+// doesn't overwrite the statuses of the other.
+func ReconcileClustersAVIPhases(
+	ctx context.Context,
+	client client.Client,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+	normalPhases []ReconcileClusterPhase,
+	deletePhases []ReconcileClusterPhase,
+) (ctrl.Result, error) {
+	return reconcileClustersPhases(ctx, client, log, obj, normalPhases, deletePhases, false)
+}
+
+func reconcileClustersPhases(
+	ctx context.Context,
+	client client.Client,
+	log logr.Logger,
+	obj *akoov1alpha1.AKODeploymentConfig,
+	normalPhases []ReconcileClusterPhase,
+	deletePhases []ReconcileClusterPhase,
+	recordStatuses bool,
 ) (ctrl.Result, error) {
 	res := ctrl.Result{}
 
@@ -75,21 +108,25 @@ func ReconcileClustersPhases(
 
 	if len(clusters.Items) == 0 {
 		log.Info("No cluster matches the selector, skip")
+		if recordStatuses {
+			obj.Status.ClusterStatuses = nil
+		}
 		return res, nil
 	}
 
 	var allErrs []error
+	var clusterStatuses []akoov1alpha1.ClusterStatus
 	// For each cluster managed by the AKODeploymentConfig, run each phase
 	// function
 	for _, cluster := range clusters.Items {
 		var errs []error
 
-		clog := log.WithValues("cluster", cluster.Namespace+"/"+cluster.Name)
+		clog := log.WithValues("cluster_name", types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}.String())
 
 		// skip reconcile if cluster is using kube-vip to provide load balancer service
 		if isLBProvider, err := ako_operator.IsLoadBalancerProvider(&cluster); err != nil {
 			log.Error(err, "can't unmarshal cluster variables")
-			allErrs = append(allErrs, err)
+			allErrs = append(allErrs, errors.Wrapf(err, "cluster %s/%s", cluster.Namespace, cluster.Name))
 			continue
 		} else if !isLBProvider {
 			log.Info(fmt.Sprintf("cluster uses kube-vip to provide load balancer type of service, skip reconciling for cluster %s/%s", cluster.Namespace, cluster.Name))
@@ -123,21 +160,91 @@ func ReconcileClustersPhases(
 			res = util.LowestNonZeroResult(res, phaseResult)
 		}
 
+		// the Cluster can be deleted while its phases run, it's skipped and its
+		// status is dropped then
+		if len(errs) > 0 && isClusterDeleted(ctx, client, &cluster, errs...) {
+			clog.Info("Cluster is deleted, skip it")
+			continue
+		}
+
 		clusterErr := kerrors.NewAggregate(errs)
 		patchOpts := []patch.Option{}
 		if clusterErr == nil {
 			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
-		} else {
-			allErrs = append(allErrs, clusterErr)
 		}
 
-		if err := patchHelper.Patch(ctx, &cluster, patchOpts...); err != nil {
-			clusterErr = kerrors.NewAggregate([]error{clusterErr, err})
-			if clusterErr != nil {
-				log.Error(clusterErr, "patch failed")
+		if err := utils.PatchWithRetry(ctx, patchHelper, &cluster, patchOpts...); err != nil {
+			if isClusterDeleted(ctx, client, &cluster, err) {
+				clog.Info("Cluster is deleted, skip it")
+				continue
 			}
+			clusterErr = kerrors.NewAggregate([]error{clusterErr, err})
+			log.Error(clusterErr, "patch failed")
 		}
+		if clusterErr != nil {
+			// name the cluster, so the failed ones can be told apart in the
+			// aggregated error
+			allErrs = append(allErrs, errors.Wrapf(clusterErr, "cluster %s/%s", cluster.Namespace, cluster.Name))
+		}
+		clusterStatuses = append(clusterStatuses, newClusterStatus(obj, &cluster, clusterErr))
+	}
+	if recordStatuses {
+		obj.Status.ClusterStatuses = clusterStatuses
 	}
 
 	return res, kerrors.NewAggregate(allErrs)
 }
+
+// isClusterDeleted returns whether one of errs is a NotFound error because the
+// Cluster is deleted, and not one of the resources its phases work on
+func isClusterDeleted(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, errs ...error) bool {
+	notFound := false
+	for _, err := range errs {
+		if apierrors.IsNotFound(err) {
+			notFound = true
+			break
+		}
+	}
+	if !notFound {
+		return false
+	}
+	err := c.Get(ctx, client.ObjectKeyFromObject(cluster), &clusterv1.Cluster{})
+	return apierrors.IsNotFound(err)
+}
+
+// newClusterStatus returns the status of AKO in the cluster after its phases
+// ran and returned err. The AKODeployed condition reflects err, and the
+// AKOAvailable condition of the cluster is copied when it's set.
+func newClusterStatus(obj *akoov1alpha1.AKODeploymentConfig, cluster *clusterv1.Cluster, err error) akoov1alpha1.ClusterStatus {
+	status := akoov1alpha1.ClusterStatus{
+		ClusterName: cluster.Name,
+		Namespace:   cluster.Namespace,
+	}
+	deployed := conditions.TrueCondition(akoov1alpha1.AKODeployedCondition)
+	if err != nil {
+		deployed = conditions.FalseCondition(akoov1alpha1.AKODeployedCondition, akoov1alpha1.AKODeploymentFailedReason,
+			clusterv1.ConditionSeverityError, err.Error())
+	}
+	status.Conditions = append(status.Conditions, *deployed)
+	if available := conditions.Get(cluster, akoov1alpha1.AKOAvailableCondition); available != nil {
+		status.Conditions = append(status.Conditions, *available)
+	}
+
+	// keep the transition time of the conditions which didn't change
+	var previous clusterv1.Conditions
+	for _, s := range obj.Status.ClusterStatuses {
+		if s.ClusterName == cluster.Name && s.Namespace == cluster.Namespace {
+			previous = s.Conditions
+		}
+	}
+	now := metav1.Now()
+	for i := range status.Conditions {
+		status.Conditions[i].LastTransitionTime = now
+		for _, p := range previous {
+			if p.Type == status.Conditions[i].Type && p.Status == status.Conditions[i].Status {
+				status.Conditions[i].LastTransitionTime = p.LastTransitionTime
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,230 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package phases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+)
+
+func unitTestClusterStatuses() {
+	var (
+		ctx                 context.Context
+		fclient             client.Client
+		akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+		phase               ReconcileClusterPhase
+	)
+
+	newCluster := func(name string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"test": "test"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		akoDeploymentConfig = &akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ako-deployment-config"},
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"test": "test"},
+				},
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(akoov1alpha1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newCluster("cluster-a"), newCluster("cluster-b"), akoDeploymentConfig,
+		).Build()
+		// AKO fails to be deployed in cluster-b only
+		phase = func(_ context.Context, _ logr.Logger, cluster *clusterv1.Cluster, _ *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+			if cluster.Name == "cluster-b" {
+				return ctrl.Result{}, errors.New("failed to create add-on secret")
+			}
+			conditions.MarkTrue(cluster, akoov1alpha1.AKOAvailableCondition)
+			return ctrl.Result{}, nil
+		}
+	})
+
+	getClusterStatus := func(name string) *akoov1alpha1.ClusterStatus {
+		for i := range akoDeploymentConfig.Status.ClusterStatuses {
+			if akoDeploymentConfig.Status.ClusterStatuses[i].ClusterName == name {
+				return &akoDeploymentConfig.Status.ClusterStatuses[i]
+			}
+		}
+		return nil
+	}
+
+	getCondition := func(status *akoov1alpha1.ClusterStatus, t clusterv1.ConditionType) *clusterv1.Condition {
+		for i := range status.Conditions {
+			if status.Conditions[i].Type == t {
+				return &status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	It("should report the status of each cluster", func() {
+		_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(akoDeploymentConfig.Status.ClusterStatuses).To(HaveLen(2))
+
+		statusA := getClusterStatus("cluster-a")
+		Expect(statusA).NotTo(BeNil())
+		Expect(statusA.Namespace).To(Equal("default"))
+		deployed := getCondition(statusA, akoov1alpha1.AKODeployedCondition)
+		Expect(deployed).NotTo(BeNil())
+		Expect(deployed.Status).To(Equal(corev1.ConditionTrue))
+		available := getCondition(statusA, akoov1alpha1.AKOAvailableCondition)
+		Expect(available).NotTo(BeNil())
+		Expect(available.Status).To(Equal(corev1.ConditionTrue))
+
+		statusB := getClusterStatus("cluster-b")
+		Expect(statusB).NotTo(BeNil())
+		deployed = getCondition(statusB, akoov1alpha1.AKODeployedCondition)
+		Expect(deployed).NotTo(BeNil())
+		Expect(deployed.Status).To(Equal(corev1.ConditionFalse))
+		Expect(deployed.Reason).To(Equal(akoov1alpha1.AKODeploymentFailedReason))
+		Expect(deployed.Message).To(ContainSubstring("failed to create add-on secret"))
+		Expect(getCondition(statusB, akoov1alpha1.AKOAvailableCondition)).To(BeNil())
+	})
+
+	It("should keep the transition time of the unchanged conditions", func() {
+		_, _ = ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+		before := getCondition(getClusterStatus("cluster-a"), akoov1alpha1.AKODeployedCondition).LastTransitionTime
+		akoDeploymentConfig.Status.ClusterStatuses[0].Conditions[0].LastTransitionTime = metav1.NewTime(before.Add(-time.Minute))
+		before = akoDeploymentConfig.Status.ClusterStatuses[0].Conditions[0].LastTransitionTime
+
+		_, _ = ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+		Expect(getCondition(getClusterStatus("cluster-a"), akoov1alpha1.AKODeployedCondition).LastTransitionTime).To(Equal(before))
+	})
+
+	When("a cluster is deleted during the reconcile", func() {
+		BeforeEach(func() {
+			akoDeploymentConfig.Status.ClusterStatuses = []akoov1alpha1.ClusterStatus{
+				{ClusterName: "cluster-a", Namespace: "default"},
+				{ClusterName: "cluster-b", Namespace: "default"},
+			}
+			phase = func(ctx context.Context, _ logr.Logger, cluster *clusterv1.Cluster, _ *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+				if cluster.Name == "cluster-a" {
+					Expect(fclient.Delete(ctx, newCluster("cluster-a"))).To(Succeed())
+					return ctrl.Result{}, apierrors.NewNotFound(clusterv1.GroupVersion.WithResource("clusters").GroupResource(), cluster.Name)
+				}
+				return ctrl.Result{}, nil
+			}
+		})
+
+		It("should skip the deleted cluster and drop its status", func() {
+			_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(akoDeploymentConfig.Status.ClusterStatuses).To(HaveLen(1))
+			Expect(getClusterStatus("cluster-a")).To(BeNil())
+			statusB := getClusterStatus("cluster-b")
+			Expect(statusB).NotTo(BeNil())
+			deployed := getCondition(statusB, akoov1alpha1.AKODeployedCondition)
+			Expect(deployed).NotTo(BeNil())
+			Expect(deployed.Status).To(Equal(corev1.ConditionTrue))
+		})
+	})
+
+	When("a resource of an existing cluster is not found", func() {
+		BeforeEach(func() {
+			phase = func(_ context.Context, _ logr.Logger, cluster *clusterv1.Cluster, _ *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+				if cluster.Name == "cluster-a" {
+					return ctrl.Result{}, apierrors.NewNotFound(corev1.Resource("secrets"), "test-cluster-avi-credentials")
+				}
+				return ctrl.Result{}, nil
+			}
+		})
+
+		It("should report the error", func() {
+			_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(akoDeploymentConfig.Status.ClusterStatuses).To(HaveLen(2))
+			deployed := getCondition(getClusterStatus("cluster-a"), akoov1alpha1.AKODeployedCondition)
+			Expect(deployed).NotTo(BeNil())
+			Expect(deployed.Status).To(Equal(corev1.ConditionFalse))
+		})
+	})
+
+	When("several clusters fail", func() {
+		BeforeEach(func() {
+			Expect(fclient.Create(ctx, newCluster("cluster-c"))).To(Succeed())
+			phase = func(_ context.Context, _ logr.Logger, cluster *clusterv1.Cluster, _ *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+				if cluster.Name == "cluster-b" {
+					return ctrl.Result{}, nil
+				}
+				return ctrl.Result{}, errors.New("failed to create add-on secret")
+			}
+		})
+
+		It("should report the errors of all the failed clusters", func() {
+			_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cluster default/cluster-a"))
+			Expect(err.Error()).To(ContainSubstring("cluster default/cluster-c"))
+			Expect(err.Error()).NotTo(ContainSubstring("cluster-b"))
+			Expect(akoDeploymentConfig.Status.ClusterStatuses).To(HaveLen(3))
+			for name, status := range map[string]corev1.ConditionStatus{
+				"cluster-a": corev1.ConditionFalse,
+				"cluster-b": corev1.ConditionTrue,
+				"cluster-c": corev1.ConditionFalse,
+			} {
+				deployed := getCondition(getClusterStatus(name), akoov1alpha1.AKODeployedCondition)
+				Expect(deployed).NotTo(BeNil())
+				Expect(deployed.Status).To(Equal(status), name)
+			}
+		})
+	})
+
+	When("the AVI phases run after AKO is deployed", func() {
+		It("should keep the cluster statuses of the AKO deployment", func() {
+			_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+			Expect(err).Should(HaveOccurred())
+			statuses := akoDeploymentConfig.Status.DeepCopy().ClusterStatuses
+
+			succeed := func(context.Context, logr.Logger, *clusterv1.Cluster, *akoov1alpha1.AKODeploymentConfig) (ctrl.Result, error) {
+				return ctrl.Result{}, nil
+			}
+			_, err = ReconcileClustersAVIPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{succeed}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(akoDeploymentConfig.Status.ClusterStatuses).To(Equal(statuses))
+		})
+	})
+
+	When("no cluster is selected", func() {
+		BeforeEach(func() {
+			akoDeploymentConfig.Spec.ClusterSelector.MatchLabels = map[string]string{"test": "none"}
+			akoDeploymentConfig.Status.ClusterStatuses = []akoov1alpha1.ClusterStatus{{ClusterName: "cluster-a", Namespace: "default"}}
+		})
+
+		It("should clear the cluster statuses", func() {
+			_, err := ReconcileClustersPhases(ctx, fclient, log.Log, akoDeploymentConfig, []ReconcileClusterPhase{phase}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(akoDeploymentConfig.Status.ClusterStatuses).To(BeEmpty())
+		})
+	})
+}
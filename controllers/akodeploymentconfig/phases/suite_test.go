@@ -51,4 +51,5 @@ func intgTests() {
 }
 
 func unitTests() {
+	Describe("Cluster statuses", unitTestClusterStatuses)
 }
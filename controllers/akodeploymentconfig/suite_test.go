@@ -36,4 +36,11 @@ func intgTests() {
 
 func unitTests() {
 	Describe("Ensure static ranges Test", unitTestEnsureStaticRanges)
+	Describe("Reconcile debounce Test", unitTestReconcileDebounce)
+	Describe("IP pool utilization Test", unitTestIPPoolUtilization)
+	Describe("Controller version compatibility Test", unitTestControllerVersionCompatibility)
+	Describe("Observed generation Test", unitTestObservedGeneration)
+	Describe("Spec diff logging Test", unitTestSpecDiffLogging)
+	Describe("AVI rate limit Test", unitTestAVIRateLimit)
+	Describe("Tenant validation Test", unitTestTenantValidation)
 }
@@ -72,6 +72,19 @@ func (r *AkoUserReconciler) ReconcileAviUserDelete(
 		log.Info("workload cluster existing, don't delete avi user")
 		return ctrl.Result{}, nil
 	}
+	return r.ReconcileAviUserDisable(ctx, log, cluster, obj)
+}
+
+// ReconcileAviUserDisable clean up all avi user account related resources of a
+// cluster which isn't selected by any AKODeploymentConfig anymore, once its AVI
+// resources are deleted
+// Note: only resources in the management cluster will be cleaned up
+func (r *AkoUserReconciler) ReconcileAviUserDisable(
+	ctx context.Context,
+	log logr.Logger,
+	cluster *clusterv1.Cluster,
+	obj *akoov1alpha1.AKODeploymentConfig,
+) (ctrl.Result, error) {
 	// Check if there is a cleanup condition in the Cluster status, if not, update it
 	if !conditions.Has(cluster, akoov1alpha1.AviUserCleanupSucceededCondition) {
 		conditions.MarkFalse(cluster, akoov1alpha1.AviUserCleanupSucceededCondition, akoov1alpha1.AviResourceCleanupReason, clusterv1.ConditionSeverityInfo, "Cleaning up the AVI load balancing user credentials before deletion")
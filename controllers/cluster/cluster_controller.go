@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
@@ -21,6 +22,11 @@ import (
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/haprovider"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/leaderelection"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/tracing"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,7 +46,7 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&source.Kind{Type: &corev1.Service{}},
 			handler.EnqueueRequestsFromMapFunc(r.serviceToCluster(r.Client, r.Log)),
 		).
-		Complete(r)
+		Complete(leaderelection.Reconciler(r))
 }
 
 type ClusterReconciler struct {
@@ -51,7 +57,18 @@ type ClusterReconciler struct {
 }
 
 func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
-	log := r.Log.WithValues("Cluster", req.NamespacedName)
+	log := r.Log.WithValues("cluster_name", req.NamespacedName.String())
+
+	ctx, span := tracing.StartReconcileSpan(ctx, "ClusterReconciler.Reconcile", attribute.String("cluster_name", req.NamespacedName.String()))
+	defer func() {
+		tracing.EndSpan(span, reterr)
+	}()
+
+	start := time.Now()
+	skipped := false
+	defer func() {
+		metrics.ObserveReconcileDuration("cluster", start, skipped, reterr)
+	}()
 
 	res := ctrl.Result{}
 	// Get the resource for this request.
@@ -59,6 +76,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	if err := r.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("Cluster not found, will not reconcile")
+			skipped = true
 			return res, nil
 		}
 		return res, err
@@ -71,7 +89,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 			cluster.GroupVersionKind(), req.NamespacedName)
 	}
 	defer func() {
-		if err := patchHelper.Patch(ctx, cluster); err != nil {
+		if err := utils.PatchWithRetry(ctx, patchHelper, cluster); err != nil {
 			if reterr == nil {
 				reterr = err
 			}
@@ -79,7 +97,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		}
 	}()
 
-	log = log.WithValues("Cluster", cluster.Namespace+"/"+cluster.Name)
+	log = log.WithValues("cluster_name", client.ObjectKeyFromObject(cluster).String())
 
 	isVIPProvider, err := ako_operator.IsControlPlaneVIPProvider(cluster)
 	if err != nil {
@@ -102,6 +120,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		return res, err
 	} else if !isLBProvider {
 		log.Info("cluster uses kube-vip to provide load balancer type of service, skip reconciling")
+		skipped = true
 		return res, nil
 	}
 
@@ -133,10 +152,10 @@ func (r *ClusterReconciler) serviceToCluster(c client.Client, log logr.Logger) h
 		if !ok {
 			log.Error(errors.New("invalid type"),
 				"Expected to receive service resource",
-				"actualType", fmt.Sprintf("%T", o))
+				"actual_type", fmt.Sprintf("%T", o))
 			return nil
 		}
-		logger := log.WithValues("service", service.Namespace+"/"+service.Name)
+		logger := log.WithValues("service_name", client.ObjectKeyFromObject(service).String())
 		if r.skipService(service) {
 			return []reconcile.Request{}
 		}
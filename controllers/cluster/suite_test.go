@@ -10,6 +10,7 @@ import (
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/cluster"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/test/builder"
@@ -29,6 +30,9 @@ var suite = builder.NewTestSuiteForController(
 
 		builder.FakeAvi = aviclient.NewFakeAviClient()
 
+		if err := controllers.SetupIndexes(mgr); err != nil {
+			return err
+		}
 		if err := (&cluster.ClusterReconciler{
 			Client: mgr.GetClient(),
 			Log:    ctrl.Log.WithName("controllers").WithName("Cluster"),
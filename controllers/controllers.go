@@ -4,25 +4,86 @@
 package controllers
 
 import (
+	"context"
+	"time"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig"
+	adccluster "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/cluster"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func SetupReconcilers(mgr ctrl.Manager) error {
-	if err := (&machine.MachineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Machine"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+// Options configures the reconcilers
+type Options struct {
+	// WorkloadClusterKubeconfigNamespace is the namespace of the workload
+	// cluster kubeconfig Secrets, the namespace of each Cluster is used when
+	// it's empty
+	WorkloadClusterKubeconfigNamespace string
+	// RequeueInterval is how long to wait before reconciling again a Cluster
+	// where AKO isn't deployed or available yet, the default one is used
+	// when it's zero
+	RequeueInterval time.Duration
+	// MachinePreTerminateHookTimeout is how long a Machine deletion can be
+	// blocked by the pre-terminate hook waiting for the AVI resources cleanup,
+	// after which the hook is removed anyway. The hook is never force removed
+	// when it's zero.
+	MachinePreTerminateHookTimeout time.Duration
+}
+
+// SetupReconcilers sets up the field indexes and all the reconcilers with mgr
+func SetupReconcilers(mgr ctrl.Manager, opts Options) error {
+	if err := SetupIndexes(mgr); err != nil {
+		return err
+	}
+	if err := SetupMachineReconciler(mgr, opts); err != nil {
 		return err
 	}
+	return SetupAKODeploymentConfigReconcilers(mgr, opts)
+}
+
+// SetupIndexes sets up the field indexes the reconcilers look up with the
+// client of mgr, it must be called once for each manager
+func SetupIndexes(mgr ctrl.Manager) error {
+	return ako_operator.IndexAKODeploymentConfigs(context.Background(), mgr.GetFieldIndexer())
+}
+
+// SetupMachineReconciler sets up the Machine reconciler with mgr
+func SetupMachineReconciler(mgr ctrl.Manager, opts Options) error {
+	machineReconciler := &machine.MachineReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Machine"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("machine-controller"),
+
+		GetAviClient: machine.NewAviClientForAKODeploymentConfig,
+	}
+	if opts.MachinePreTerminateHookTimeout != 0 {
+		timeout := opts.MachinePreTerminateHookTimeout
+		machineReconciler.MachinePreTerminateHookTimeout = &timeout
+	}
+	return machineReconciler.SetupWithManager(mgr)
+}
 
+// SetupAKODeploymentConfigReconcilers sets up the AKODeploymentConfig and
+// Cluster reconcilers with mgr
+func SetupAKODeploymentConfigReconcilers(mgr ctrl.Manager, opts Options) error {
+	log := ctrl.Log.WithName("controllers").WithName("AKODeploymentConfig")
+	clusterReconciler := adccluster.NewReconciler(mgr.GetClient(), log, mgr.GetScheme())
+	clusterReconciler.GetRemoteClient = adccluster.NewClusterClientGetter(opts.WorkloadClusterKubeconfigNamespace)
+	if opts.RequeueInterval != 0 {
+		clusterReconciler.RequeueInterval = opts.RequeueInterval
+	}
 	if err := (&akodeploymentconfig.AKODeploymentConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("AKODeploymentConfig"),
-		Scheme: mgr.GetScheme(),
+		Client:            mgr.GetClient(),
+		Log:               log,
+		Scheme:            mgr.GetScheme(),
+		Recorder:          mgr.GetEventRecorderFor(akoov1alpha1.AKODeploymentConfigControllerName),
+		ClusterReconciler: clusterReconciler,
+
+		ReconcileDebounceInterval: akodeploymentconfig.DefaultReconcileDebounceInterval,
 	}).SetupWithManager(mgr); err != nil {
 		return err
 	}
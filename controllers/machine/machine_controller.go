@@ -5,6 +5,8 @@ package machine
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
 
@@ -13,15 +15,25 @@ import (
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/handlers"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/haprovider"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/leaderelection"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/tracing"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -35,19 +47,122 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&source.Kind{Type: &clusterv1.Cluster{}},
 			handler.EnqueueRequestsFromMapFunc(handlers.MachinesForCluster(r.Client, r.Log)),
+			builder.WithPredicates(predicate.Or(AviClusterLabelChangedPredicate(), ClusterUnpausedPredicate(), ClusterDeletionChangedPredicate())),
 		).
-		Complete(r)
+		Complete(leaderelection.Reconciler(r))
+}
+
+// AviClusterLabelChangedPredicate only passes Cluster events where the AVI
+// cluster label has been added, removed or changed, so that unrelated Cluster
+// updates (e.g. status changes) don't re-enqueue all of its Machines
+func AviClusterLabelChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			_, exist := e.Object.GetLabels()[akoov1alpha1.AviClusterLabel]
+			return exist
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldValue, oldExist := e.ObjectOld.GetLabels()[akoov1alpha1.AviClusterLabel]
+			newValue, newExist := e.ObjectNew.GetLabels()[akoov1alpha1.AviClusterLabel]
+			return oldExist != newExist || oldValue != newValue
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, exist := e.Object.GetLabels()[akoov1alpha1.AviClusterLabel]
+			return exist
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// ClusterUnpausedPredicate only passes Cluster update events where the Cluster
+// is unpaused, so that its Machines skipped while it was paused are reconciled
+func ClusterUnpausedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok := e.ObjectOld.(*clusterv1.Cluster)
+			if !ok {
+				return false
+			}
+			newCluster, ok := e.ObjectNew.(*clusterv1.Cluster)
+			if !ok {
+				return false
+			}
+			return oldCluster.Spec.Paused && !newCluster.Spec.Paused
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// ClusterDeletionChangedPredicate only passes Cluster update events where the
+// Cluster starts being deleted or its AVI finalizer is removed, so that the
+// pre-terminate hook of its Machines is removed once the AVI cleanup finishes
+func ClusterDeletionChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() {
+				return true
+			}
+			return ctrlutil.ContainsFinalizer(e.ObjectOld, akoov1alpha1.ClusterFinalizer) &&
+				!ctrlutil.ContainsFinalizer(e.ObjectNew, akoov1alpha1.ClusterFinalizer)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
 }
 
 type MachineReconciler struct {
 	client.Client
-	Log        logr.Logger
-	Scheme     *runtime.Scheme
-	Haprovider *haprovider.HAProvider
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+
+	// GetAviClient is used to check the AVI health of the Machines, the
+	// check is skipped when it's nil
+	GetAviClient AviClientGetter
+	// aviClients maps an AKODeploymentConfig name to its cachedAviClient, so
+	// the Machines don't log in to AVI on every reconcile
+	aviClients sync.Map
+	// akoHealthCheckedAt maps a Machine key to the time.Time its AKOHealthy
+	// condition was last refreshed
+	akoHealthCheckedAt sync.Map
+
+	Recorder record.EventRecorder
+	// MachinePreTerminateHookTimeout is how long a Machine can be blocked by
+	// the pre-terminate hook while the Cluster finalizer is still present,
+	// after which the hook is removed anyway. The hook is never force removed
+	// when it's nil.
+	MachinePreTerminateHookTimeout *time.Duration
 }
 
 func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
-	log := r.Log.WithValues("Machine", req.NamespacedName)
+	log := r.Log.WithValues("machine_name", req.NamespacedName.String())
+
+	ctx, span := tracing.StartReconcileSpan(ctx, "MachineReconciler.Reconcile", attribute.String("machine_name", req.NamespacedName.String()))
+	defer func() {
+		tracing.EndSpan(span, reterr)
+	}()
+
+	start := time.Now()
+	skipped := false
+	defer func() {
+		metrics.ObserveReconcileDuration("machine", start, skipped, reterr)
+	}()
 
 	res := ctrl.Result{}
 	// Get the resource for this request.
@@ -55,6 +170,8 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("Machine not found, will not reconcile")
+			r.akoHealthCheckedAt.Delete(req.NamespacedName)
+			skipped = true
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -67,7 +184,7 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 			obj.GroupVersionKind(), req.NamespacedName)
 	}
 	defer func() {
-		if err := patchHelper.Patch(ctx, obj); err != nil {
+		if err := utils.PatchWithRetry(ctx, patchHelper, obj); err != nil {
 			if reterr == nil {
 				reterr = err
 			}
@@ -79,6 +196,7 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	clusterName, exist := obj.Labels[clusterv1.ClusterLabelName]
 	if !exist {
 		log.Info("machine doesn't have cluster name label, skip reconciling")
+		skipped = true
 		return res, nil
 	}
 
@@ -91,42 +209,60 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		return res, err
 	}
 
-	log = log.WithValues("Cluster", cluster.Namespace+"/"+cluster.Name)
+	res, skipped, err = r.reconcileMachine(ctx, log, obj, cluster)
+	return res, err
+}
+
+// reconcileMachine reconciles the Machine against its Cluster, it also returns
+// whether the reconciling is skipped
+func (r *MachineReconciler) reconcileMachine(
+	ctx context.Context,
+	log logr.Logger,
+	obj *clusterv1.Machine,
+	cluster *clusterv1.Cluster,
+) (ctrl.Result, bool, error) {
+	res := ctrl.Result{}
+	log = log.WithValues("cluster_name", client.ObjectKeyFromObject(cluster).String())
+
+	// mirror CAPI, which doesn't reconcile the Machines of a paused Cluster
+	if annotations.IsPaused(cluster, obj) {
+		log.Info("Cluster or Machine is paused, skip reconciling")
+		return res, true, nil
+	}
 
 	isVIPProvider, err := ako_operator.IsControlPlaneVIPProvider(cluster)
 	if err != nil {
 		log.Error(err, "can't unmarshal cluster variables")
-		return res, err
+		return res, false, err
 	}
 
 	if isVIPProvider {
-		r.Haprovider = haprovider.NewProvider(r.Client, log)
-		if err = r.Haprovider.CreateOrUpdateHAEndpoints(ctx, obj); err != nil {
+		if err = haprovider.NewProvider(r.Client, log).CreateOrUpdateHAEndpoints(ctx, obj); err != nil {
 			log.Error(err, "Fail to reconcile HA endpoint")
-			return res, err
+			return res, false, err
 		}
 	}
 
 	// skip reconcile if cluster is using kube-vip to provide load balancer service
 	if isLBProvider, err := ako_operator.IsLoadBalancerProvider(cluster); err != nil {
 		log.Error(err, "can't unmarshal cluster variables")
-		return res, err
+		return res, false, err
 	} else if !isLBProvider {
 		log.Info("cluster uses kube-vip to provide load balancer type of service, skip reconciling")
-		return res, nil
+		return res, true, nil
 	}
 
 	if _, exist := cluster.Labels[akoov1alpha1.AviClusterLabel]; !exist {
-		delete(obj.Annotations, akoov1alpha1.PreTerminateAnnotation)
+		ako_operator.RemovePreTerminateHook(obj)
 		log.Info("Cluster doesn't have AVI enabled, PreTerminateAnnotation deleted, skip reconciling")
-		return res, nil
+		return res, true, nil
 	}
 
 	// Removes the pre-terminate hook when machine is being deleted directly and it's parent cluster is not.
 	if !obj.GetDeletionTimestamp().IsZero() && cluster.GetDeletionTimestamp().IsZero() {
-		delete(obj.Annotations, akoov1alpha1.PreTerminateAnnotation)
+		ako_operator.RemovePreTerminateHook(obj)
 		log.Info("Machine is being deleted though its parent Cluster is not, removing pre-terminate hook")
-		return res, nil
+		return res, false, nil
 	}
 
 	// Handle deleted cluster resources.
@@ -134,17 +270,18 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		res, err := r.reconcileClusterDelete(ctx, log, obj, cluster)
 		if err != nil {
 			log.Error(err, "failed to reconcile Machine deletion")
-			return res, err
+			return res, false, err
 		}
-		return res, nil
+		return res, false, nil
 	}
 
 	// Handle non-deleted resources.
-	if res, err := r.reconcileNormal(ctx, log, obj, cluster); err != nil {
+	res, err = r.reconcileNormal(ctx, log, obj, cluster)
+	if err != nil {
 		log.Error(err, "failed to reconcile Machine")
-		return res, err
+		return res, false, err
 	}
-	return res, nil
+	return res, false, nil
 }
 
 func (r *MachineReconciler) reconcileClusterDelete(
@@ -167,17 +304,45 @@ func (r *MachineReconciler) reconcileNormal(
 ) (ctrl.Result, error) {
 	log.Info("Start reconciling")
 
+	// the hook is only needed once the node of the Machine can back AVI virtual
+	// services, hooks placed in earlier phases are kept. The Machine is
+	// reconciled again when its phase changes.
+	if _, exist := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]; !exist &&
+		obj.Status.Phase != string(clusterv1.MachinePhaseRunning) {
+		log.Info("Machine is not running yet, skip adding the pre-terminate hook", "phase", obj.Status.Phase)
+		return ctrl.Result{}, nil
+	}
+
+	if obj.Status.Phase == string(clusterv1.MachinePhaseRunning) {
+		reconcileMachineIPAnnotation(log, obj)
+	}
+
+	adc, err := r.clusterAKODeploymentConfig(ctx, log, cluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// the AKODeploymentConfig removes the hooks it owns while it's deleted,
+	// placing them again would block the Machine deletions
+	if adc != nil && !adc.GetDeletionTimestamp().IsZero() {
+		log.Info("AKODeploymentConfig is being deleted, skip adding the pre-terminate hook", "adc", adc.Name)
+		return ctrl.Result{}, nil
+	}
+
 	// Add pre-terminate machine deletion phase hook if it doesn't exist
 	if _, exist := obj.Annotations[clusterv1.PreTerminateDeleteHookAnnotationPrefix]; !exist {
 		if obj.Annotations == nil {
 			obj.Annotations = make(map[string]string)
 		}
-		if cluster.Namespace != akoov1alpha1.TKGSystemNamespace {
-			obj.Annotations[akoov1alpha1.PreTerminateAnnotation] = "ako-operator"
+		// keep the existing value, which records when the hook was placed
+		if _, exist := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]; !exist && cluster.Namespace != akoov1alpha1.TKGSystemNamespace {
+			obj.Annotations[akoov1alpha1.PreTerminateAnnotation] = preTerminateAnnotationValue(time.Now())
 		}
 	}
+	if _, exist := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]; exist && adc != nil {
+		r.reconcilePreTerminateHookOwner(log, obj, adc)
+	}
 
-	return ctrl.Result{}, nil
+	return r.reconcileAKOHealthyCondition(ctx, log, obj, cluster)
 }
 
 // reconcileMachineDeletionHook removes the pre-terminate hook when the finalizer on the Cluster
@@ -193,15 +358,41 @@ func (r *MachineReconciler) reconcileMachineDeletionHook(
 	res := ctrl.Result{}
 
 	if ctrlutil.ContainsFinalizer(cluster, akoov1alpha1.ClusterFinalizer) {
+		if remaining, timedOut := r.preTerminateHookTimeoutRemaining(obj); timedOut {
+			log.Info("Pre-terminate hook timed out while cluster finalizer is still present, removing pre-terminate hook",
+				"finalizer", akoov1alpha1.ClusterFinalizer, "timeout", r.MachinePreTerminateHookTimeout.String())
+			r.Recorder.Eventf(obj, corev1.EventTypeWarning, akoov1alpha1.PreTerminateHookTimeoutReason,
+				"Removed pre-terminate hook after %s, AVI resources of cluster %s/%s may not be cleaned up",
+				r.MachinePreTerminateHookTimeout.String(), cluster.Namespace, cluster.Name)
+			ako_operator.RemovePreTerminateHook(obj)
+			return res, nil
+		} else if remaining > 0 {
+			res.RequeueAfter = remaining
+		}
 		log.Info("Cluster has finalizer set. Clean up has not finished. Will skip reconciling", "finalizer", akoov1alpha1.ClusterFinalizer)
 		return res, nil
 	}
 
 	if annotations.HasWithPrefix(clusterv1.PreTerminateDeleteHookAnnotationPrefix, obj.ObjectMeta.Annotations) {
 		// Removes the pre-terminate hook as the cleanup has finished
-		delete(obj.Annotations, akoov1alpha1.PreTerminateAnnotation)
+		ako_operator.RemovePreTerminateHook(obj)
 		log.Info("Removing pre-terminate hook")
 	}
 
 	return res, nil
 }
+
+// preTerminateHookTimeoutRemaining returns how long the pre-terminate hook can
+// stay on the Machine before it times out, and whether it has already timed
+// out. The hook only blocks the Machine once it's deleted, so the timeout is
+// measured from the Machine deletion time rather than when the hook was placed.
+func (r *MachineReconciler) preTerminateHookTimeoutRemaining(obj *clusterv1.Machine) (time.Duration, bool) {
+	if r.MachinePreTerminateHookTimeout == nil || obj.GetDeletionTimestamp().IsZero() {
+		return 0, false
+	}
+	if _, exist := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]; !exist {
+		return 0, false
+	}
+	remaining := time.Until(obj.GetDeletionTimestamp().Add(*r.MachinePreTerminateHookTimeout))
+	return remaining, remaining <= 0
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AKOHealthCheckInterval is how often the AKOHealthy condition of a
+	// Machine is refreshed
+	AKOHealthCheckInterval = time.Minute * 5
+
+	aviVirtualServiceOperUp = "OPER_UP"
+)
+
+// AviClientGetter returns an AVI client for the given AKODeploymentConfig
+type AviClientGetter func(ctx context.Context, c client.Client, log logr.Logger, adc *akoov1alpha1.AKODeploymentConfig) (aviclient.Client, error)
+
+// NewAviClientForAKODeploymentConfig initializes an AVI client from the
+// credentials referenced by the AKODeploymentConfig
+func NewAviClientForAKODeploymentConfig(ctx context.Context, c client.Client, log logr.Logger, adc *akoov1alpha1.AKODeploymentConfig) (aviclient.Client, error) {
+	aviClient, err := aviclient.NewAviClientFromSecrets(c, ctx, log, adc.Spec.Controller,
+		adc.Spec.AdminCredentialRef.Name, adc.Spec.AdminCredentialRef.Namespace,
+		adc.Spec.CertificateAuthorityRef.Name, adc.Spec.CertificateAuthorityRef.Namespace,
+		adc.Spec.ControllerVersion)
+	if err != nil {
+		return nil, err
+	}
+	return aviClient, nil
+}
+
+// cachedAviClient is the AVI client of an AKODeploymentConfig, it's
+// recreated once the AKODeploymentConfig spec changes
+type cachedAviClient struct {
+	generation int64
+	client     aviclient.Client
+}
+
+// reconcileAKOHealthyCondition refreshes the AKOHealthy condition of the
+// Machine so operators can check AVI is healthy for the node before upgrading
+// it. The condition is refreshed at most once per AKOHealthCheckInterval.
+func (r *MachineReconciler) reconcileAKOHealthyCondition(
+	ctx context.Context,
+	log logr.Logger,
+	obj *clusterv1.Machine,
+	cluster *clusterv1.Cluster,
+) (ctrl.Result, error) {
+	res := ctrl.Result{}
+	if r.GetAviClient == nil {
+		return res, nil
+	}
+
+	key := client.ObjectKeyFromObject(obj)
+	if checkedAt, ok := r.akoHealthCheckedAt.Load(key); ok {
+		if wait := AKOHealthCheckInterval - time.Since(checkedAt.(time.Time)); wait > 0 {
+			log.V(3).Info("AKO health was checked recently, skip", "after", wait.String())
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	adc, err := ako_operator.GetAKODeploymentConfigForCluster(ctx, r.Client, log, cluster)
+	if err != nil {
+		log.Error(err, "failed to get cluster matched akodeploymentconfig")
+		return res, err
+	}
+	if adc == nil {
+		log.Info("Not find cluster matched akodeploymentconfig, skip AKO health check")
+		return res, nil
+	}
+
+	aviClient, err := r.aviClientFor(ctx, log, adc)
+	if err != nil {
+		log.Error(err, "Cannot init AVI client for AKO health check")
+		conditions.MarkUnknown(obj, akoov1alpha1.AKOHealthyCondition, akoov1alpha1.AviHealthCheckFailedReason, err.Error())
+		return res, err
+	}
+
+	if err := SetAKOHealthyCondition(aviClient, obj); err != nil {
+		log.Error(err, "Failed to check AVI virtual service health")
+		// log in again on the next check, e.g. the credentials are rotated
+		r.aviClients.Delete(adc.Name)
+		return res, err
+	}
+	r.akoHealthCheckedAt.Store(key, time.Now())
+	return ctrl.Result{RequeueAfter: AKOHealthCheckInterval}, nil
+}
+
+// aviClientFor returns the cached AVI client of the AKODeploymentConfig, and
+// creates it when there is none for its current spec
+func (r *MachineReconciler) aviClientFor(ctx context.Context, log logr.Logger, adc *akoov1alpha1.AKODeploymentConfig) (aviclient.Client, error) {
+	if cached, ok := r.aviClients.Load(adc.Name); ok && cached.(cachedAviClient).generation == adc.Generation {
+		return cached.(cachedAviClient).client, nil
+	}
+	aviClient, err := r.GetAviClient(ctx, r.Client, log, adc)
+	if err != nil {
+		return nil, err
+	}
+	r.aviClients.Store(adc.Name, cachedAviClient{generation: adc.Generation, client: aviClient})
+	return aviClient, nil
+}
+
+// SetAKOHealthyCondition sets the AKOHealthy condition of the Machine to True
+// when all the AVI virtual services backed by the machine's IPs are up, and
+// to False otherwise
+func SetAKOHealthyCondition(aviClient aviclient.Client, obj *clusterv1.Machine) error {
+	states := map[string]string{}
+	for _, ip := range machineIPs(obj) {
+		vsStates, err := aviClient.VirtualServiceOperStatesByServerIP(ip)
+		if err != nil {
+			conditions.MarkUnknown(obj, akoov1alpha1.AKOHealthyCondition, akoov1alpha1.AviHealthCheckFailedReason, err.Error())
+			return err
+		}
+		for name, state := range vsStates {
+			states[name] = state
+		}
+	}
+
+	if len(states) == 0 {
+		conditions.MarkFalse(obj, akoov1alpha1.AKOHealthyCondition, akoov1alpha1.AviVirtualServiceNotFoundReason,
+			clusterv1.ConditionSeverityInfo, "No AVI virtual service found for the machine")
+		return nil
+	}
+
+	var down []string
+	for name, state := range states {
+		if state != aviVirtualServiceOperUp {
+			down = append(down, name)
+		}
+	}
+	if len(down) > 0 {
+		sort.Strings(down)
+		conditions.MarkFalse(obj, akoov1alpha1.AKOHealthyCondition, akoov1alpha1.AviVirtualServiceDownReason,
+			clusterv1.ConditionSeverityWarning, fmt.Sprintf("AVI virtual services %s are not up", strings.Join(down, ",")))
+		return nil
+	}
+	conditions.MarkTrue(obj, akoov1alpha1.AKOHealthyCondition)
+	return nil
+}
+
+// machineIPs returns the distinct IPs of the Machine
+func machineIPs(obj *clusterv1.Machine) []string {
+	var ips []string
+	seen := map[string]bool{}
+	for _, address := range obj.Status.Addresses {
+		if address.Type != clusterv1.MachineExternalIP && address.Type != clusterv1.MachineInternalIP {
+			continue
+		}
+		if !seen[address.Address] {
+			seen[address.Address] = true
+			ips = append(ips, address.Address)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func unitTestAKOHealthyCondition() {
+	var (
+		server    *httptest.Server
+		aviClient aviclient.Client
+		obj       *clusterv1.Machine
+		vsState   string
+		err       error
+	)
+
+	BeforeEach(func() {
+		vsState = "OPER_UP"
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch {
+			case req.URL.Path == "/login":
+				_, _ = w.Write([]byte(`{}`))
+			case strings.HasPrefix(req.URL.Path, "/api/pool-inventory") && req.URL.Query().Get("page") == "2":
+				_, _ = w.Write([]byte(`{"count": 3, "results": [` +
+					`{"config": {"name": "pool-3", "servers": [{"ip": {"addr": "10.0.0.8", "type": "V4"}}]}, "virtualservices": ["https://avi/api/virtualservice/virtualservice-1#vs-1"]}]}`))
+			case strings.HasPrefix(req.URL.Path, "/api/pool-inventory"):
+				_, _ = w.Write([]byte(`{"count": 3, "next": "https://avi/api/pool-inventory?page=2", "results": [` +
+					`{"config": {"name": "pool-1", "servers": [{"ip": {"addr": "10.0.0.5", "type": "V4"}}]}, "virtualservices": ["https://avi/api/virtualservice/virtualservice-1#vs-1"]},` +
+					`{"config": {"name": "pool-2", "servers": [{"ip": {"addr": "10.0.0.6", "type": "V4"}}]}, "virtualservices": ["https://avi/api/virtualservice/virtualservice-2#vs-2"]}]}`))
+			case req.URL.Path == "/api/virtualservice-inventory/virtualservice-1":
+				_, _ = w.Write([]byte(`{"config": {"name": "vs-1"}, "runtime": {"oper_status": {"state": "` + vsState + `"}}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		aviClient, err = aviclient.NewAviClient(&aviclient.AviClientConfig{
+			ServerIP: strings.TrimPrefix(server.URL, "https://"),
+			Username: "admin",
+			Password: "Admin!23",
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+			},
+		}, "20.1.3")
+		Expect(err).ShouldNot(HaveOccurred())
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+			},
+			Status: clusterv1.MachineStatus{
+				Addresses: clusterv1.MachineAddresses{
+					{Type: clusterv1.MachineExternalIP, Address: "10.0.0.5"},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		err = machine.SetAKOHealthyCondition(aviClient, obj)
+	})
+
+	When("the virtual service of the machine is up", func() {
+		It("should set AKOHealthy condition to true", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conditions.IsTrue(obj, akoov1alpha1.AKOHealthyCondition)).To(BeTrue())
+		})
+	})
+
+	When("the virtual service of the machine is down", func() {
+		BeforeEach(func() {
+			vsState = "OPER_DOWN"
+		})
+
+		It("should set AKOHealthy condition to false", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conditions.IsFalse(obj, akoov1alpha1.AKOHealthyCondition)).To(BeTrue())
+			Expect(conditions.GetReason(obj, akoov1alpha1.AKOHealthyCondition)).To(Equal(akoov1alpha1.AviVirtualServiceDownReason))
+			Expect(conditions.GetMessage(obj, akoov1alpha1.AKOHealthyCondition)).To(ContainSubstring("vs-1"))
+		})
+	})
+
+	When("the pool of the machine is on the next page", func() {
+		BeforeEach(func() {
+			obj.Status.Addresses = clusterv1.MachineAddresses{
+				{Type: clusterv1.MachineExternalIP, Address: "10.0.0.8"},
+			}
+		})
+
+		It("should set AKOHealthy condition to true", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conditions.IsTrue(obj, akoov1alpha1.AKOHealthyCondition)).To(BeTrue())
+		})
+	})
+
+	When("no virtual service is associated with the machine", func() {
+		BeforeEach(func() {
+			obj.Status.Addresses = clusterv1.MachineAddresses{
+				{Type: clusterv1.MachineExternalIP, Address: "10.0.0.7"},
+			}
+		})
+
+		It("should set AKOHealthy condition to false", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conditions.IsFalse(obj, akoov1alpha1.AKOHealthyCondition)).To(BeTrue())
+			Expect(conditions.GetReason(obj, akoov1alpha1.AKOHealthyCondition)).To(Equal(akoov1alpha1.AviVirtualServiceNotFoundReason))
+		})
+	})
+
+	When("the virtual service can't be queried", func() {
+		BeforeEach(func() {
+			obj.Status.Addresses = clusterv1.MachineAddresses{
+				{Type: clusterv1.MachineInternalIP, Address: "10.0.0.6"},
+			}
+		})
+
+		It("should return error and set AKOHealthy condition to unknown", func() {
+			Expect(err).Should(HaveOccurred())
+			Expect(conditions.Get(obj, akoov1alpha1.AKOHealthyCondition).Status).To(Equal(corev1.ConditionUnknown))
+		})
+	})
+}
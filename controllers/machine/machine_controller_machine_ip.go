@@ -0,0 +1,31 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine
+
+import (
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// reconcileMachineIPAnnotation records the ExternalIP the HA provider adds to
+// the control plane Endpoints in the RecordedMachineIPAnnotation, and updates
+// it when the IP changes. The HA provider can then remove the IP at deletion
+// without the Machine addresses. A Machine without such an IP is left as is.
+func reconcileMachineIPAnnotation(log logr.Logger, obj *clusterv1.Machine) {
+	ip := utils.GetMachineExternalIP(obj)
+	if ip == "" {
+		log.V(3).Info("Machine has no external IP, skip recording it")
+		return
+	}
+	if obj.Annotations[akoov1alpha1.RecordedMachineIPAnnotation] == ip {
+		return
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[akoov1alpha1.RecordedMachineIPAnnotation] = ip
+	log.Info("Recorded the Machine IP", "ip", ip)
+}
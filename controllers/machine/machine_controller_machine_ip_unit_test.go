@@ -0,0 +1,117 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestMachineIPAnnotation() {
+	var (
+		ctx     context.Context
+		fclient client.Client
+		obj     *clusterv1.Machine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+			Status: clusterv1.MachineStatus{
+				Phase: string(clusterv1.MachinePhaseRunning),
+				Addresses: clusterv1.MachineAddresses{
+					{Type: clusterv1.MachineExternalIP, Address: "192.168.0.1"},
+					{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: "",
+				},
+			},
+		}).Build()
+		reconciler := &machine.MachineReconciler{
+			Client: fclient,
+			Log:    log.Log,
+			Scheme: scheme,
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	})
+
+	It("should record the ExternalIP of the machine status", func() {
+		Expect(obj.Annotations).To(HaveKeyWithValue(akoov1alpha1.RecordedMachineIPAnnotation, "192.168.0.1"))
+	})
+
+	When("the machine IP changes", func() {
+		BeforeEach(func() {
+			obj.Annotations = map[string]string{akoov1alpha1.RecordedMachineIPAnnotation: "192.168.0.2"}
+		})
+
+		It("should update the recorded IP", func() {
+			Expect(obj.Annotations).To(HaveKeyWithValue(akoov1alpha1.RecordedMachineIPAnnotation, "192.168.0.1"))
+		})
+	})
+
+	When("the machine addresses are gone", func() {
+		BeforeEach(func() {
+			obj.Status.Addresses = nil
+			obj.Annotations = map[string]string{akoov1alpha1.RecordedMachineIPAnnotation: "10.0.0.2"}
+		})
+
+		It("should keep the recorded IP", func() {
+			Expect(obj.Annotations).To(HaveKeyWithValue(akoov1alpha1.RecordedMachineIPAnnotation, "10.0.0.2"))
+		})
+	})
+
+	When("the machine has no external IP", func() {
+		BeforeEach(func() {
+			obj.Status.Addresses = clusterv1.MachineAddresses{
+				{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"},
+			}
+		})
+
+		It("should not record any IP", func() {
+			Expect(obj.Annotations).NotTo(HaveKey(akoov1alpha1.RecordedMachineIPAnnotation))
+		})
+	})
+
+	When("the machine is not running yet", func() {
+		BeforeEach(func() {
+			obj.Status.Phase = string(clusterv1.MachinePhaseProvisioned)
+		})
+
+		It("should not record the IP", func() {
+			Expect(obj.Annotations).NotTo(HaveKey(akoov1alpha1.RecordedMachineIPAnnotation))
+		})
+	})
+}
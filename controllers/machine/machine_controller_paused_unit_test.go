@@ -0,0 +1,104 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestPausedCluster() {
+	var (
+		ctx        context.Context
+		fclient    client.Client
+		reconciler *machine.MachineReconciler
+		obj        *clusterv1.Machine
+		cluster    *clusterv1.Cluster
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+		}
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: "",
+				},
+			},
+			Spec: clusterv1.ClusterSpec{
+				Paused: true,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, cluster).Build()
+		reconciler = &machine.MachineReconciler{
+			Client: fclient,
+			Log:    log.Log,
+			Scheme: scheme,
+		}
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(res).To(Equal(ctrl.Result{}))
+	})
+
+	When("the machine doesn't have the pre-terminate hook", func() {
+		It("should not add the hook", func() {
+			got := &clusterv1.Machine{}
+			Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), got)).To(Succeed())
+			Expect(got.Annotations).To(BeEmpty())
+		})
+	})
+
+	When("the cluster is being deleted", func() {
+		BeforeEach(func() {
+			now := metav1.Now()
+			cluster.DeletionTimestamp = &now
+			obj.Annotations = map[string]string{akoov1alpha1.PreTerminateAnnotation: "ako-operator"}
+		})
+
+		It("should not remove the hook", func() {
+			got := &clusterv1.Machine{}
+			Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), got)).To(Succeed())
+			Expect(got.Annotations).To(Equal(map[string]string{akoov1alpha1.PreTerminateAnnotation: "ako-operator"}))
+		})
+	})
+
+	Context("Cluster watch predicate", func() {
+		It("should only pass the cluster update events which unpause the cluster", func() {
+			unpaused := cluster.DeepCopy()
+			unpaused.Spec.Paused = false
+			p := machine.ClusterUnpausedPredicate()
+			Expect(p.Update(event.UpdateEvent{ObjectOld: cluster, ObjectNew: unpaused})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: unpaused, ObjectNew: cluster})).To(BeFalse())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: cluster, ObjectNew: cluster})).To(BeFalse())
+			Expect(p.Create(event.CreateEvent{Object: unpaused})).To(BeFalse())
+		})
+	})
+}
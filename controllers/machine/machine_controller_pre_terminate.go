@@ -0,0 +1,91 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/version"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultMachinePreTerminateHookTimeout is how long the pre-terminate hook
+// blocks a Machine deletion waiting for the AVI resources cleanup
+const DefaultMachinePreTerminateHookTimeout = time.Hour
+
+// preTerminateHookOwner identifies AKO Operator as the owner of the
+// pre-terminate hook
+const preTerminateHookOwner = "ako-operator"
+
+// PreTerminateHookValue is the value of the pre-terminate hook annotation AKO
+// Operator adds to Machines, so tools can tell which operator placed the hook
+type PreTerminateHookValue struct {
+	Operator  string `json:"operator"`
+	Version   string `json:"version"`
+	Timestamp string `json:"timestamp"`
+}
+
+// preTerminateAnnotationValue returns the pre-terminate hook annotation value
+// placed at the given time
+func preTerminateAnnotationValue(now time.Time) string {
+	value, _ := json.Marshal(&PreTerminateHookValue{
+		Operator:  preTerminateHookOwner,
+		Version:   version.Version,
+		Timestamp: now.UTC().Format(time.RFC3339),
+	})
+	return string(value)
+}
+
+// ParsePreTerminateAnnotationValue parses the pre-terminate hook annotation
+// value. Values placed by older AKO Operator versions are plain strings, they
+// are returned as the Operator with no version or timestamp.
+func ParsePreTerminateAnnotationValue(value string) *PreTerminateHookValue {
+	hook := &PreTerminateHookValue{}
+	if err := json.Unmarshal([]byte(value), hook); err != nil {
+		return &PreTerminateHookValue{Operator: value}
+	}
+	return hook
+}
+
+// clusterAKODeploymentConfig returns the AKODeploymentConfig named by the avi
+// label of the Cluster, nil when there is none
+func (r *MachineReconciler) clusterAKODeploymentConfig(
+	ctx context.Context,
+	log logr.Logger,
+	cluster *clusterv1.Cluster,
+) (*akoov1alpha1.AKODeploymentConfig, error) {
+	adcName := cluster.Labels[akoov1alpha1.AviClusterLabel]
+	if adcName == "" {
+		return nil, nil
+	}
+	adc := &akoov1alpha1.AKODeploymentConfig{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: adcName}, adc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get AKODeploymentConfig", "adc", adcName)
+		return nil, err
+	}
+	return adc, nil
+}
+
+// reconcilePreTerminateHookOwner makes the AKODeploymentConfig an owner of the
+// pre-terminate hook of the Machine, so the hook is removed when the
+// AKODeploymentConfig is deleted
+func (r *MachineReconciler) reconcilePreTerminateHookOwner(
+	log logr.Logger,
+	obj *clusterv1.Machine,
+	adc *akoov1alpha1.AKODeploymentConfig,
+) {
+	if !ako_operator.SetPreTerminateHookOwner(obj, adc) {
+		log.V(3).Info("Machine has no controller, skip adding the AKODeploymentConfig owner reference")
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestPreTerminateAnnotation() {
+	var (
+		ctx        context.Context
+		fclient    client.Client
+		reconciler *machine.MachineReconciler
+		obj        *clusterv1.Machine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+			Status: clusterv1.MachineStatus{
+				Phase: string(clusterv1.MachinePhaseRunning),
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: "",
+				},
+			},
+		}).Build()
+		reconciler = &machine.MachineReconciler{
+			Client: fclient,
+			Log:    log.Log,
+			Scheme: scheme,
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	})
+
+	When("the machine doesn't have the pre-terminate hook", func() {
+		It("should add the hook with a structured value", func() {
+			value, ok := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]
+			Expect(ok).To(BeTrue())
+
+			hook := &machine.PreTerminateHookValue{}
+			Expect(json.Unmarshal([]byte(value), hook)).To(Succeed())
+			Expect(hook.Operator).To(Equal("ako-operator"))
+			Expect(hook.Version).To(Equal(version.Version))
+			_, err := time.Parse(time.RFC3339, hook.Timestamp)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(machine.ParsePreTerminateAnnotationValue(value)).To(Equal(hook))
+		})
+	})
+
+	When("the machine has the pre-terminate hook placed by an older version", func() {
+		BeforeEach(func() {
+			obj.Annotations = map[string]string{
+				akoov1alpha1.PreTerminateAnnotation: "ako-operator",
+			}
+		})
+
+		It("should keep the old value", func() {
+			value := obj.Annotations[akoov1alpha1.PreTerminateAnnotation]
+			Expect(value).To(Equal("ako-operator"))
+			Expect(machine.ParsePreTerminateAnnotationValue(value)).To(Equal(&machine.PreTerminateHookValue{
+				Operator: "ako-operator",
+			}))
+		})
+	})
+
+	It("should not panic on malformed values", func() {
+		for _, value := range []string{"", "{", "null", `{"operator":1}`} {
+			Expect(func() {
+				machine.ParsePreTerminateAnnotationValue(value)
+			}).NotTo(Panic())
+		}
+	})
+}
+
+func unitTestPreTerminateHookTimeout() {
+	var (
+		ctx        context.Context
+		fclient    client.Client
+		recorder   *record.FakeRecorder
+		reconciler *machine.MachineReconciler
+		obj        *clusterv1.Machine
+		timeout    *time.Duration
+		res        ctrl.Result
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deletedAt := metav1.Now()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-machine",
+				Namespace:         "default",
+				DeletionTimestamp: &deletedAt,
+				Finalizers:        []string{clusterv1.MachineFinalizer},
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+				Annotations: map[string]string{
+					akoov1alpha1.PreTerminateAnnotation: `{"operator":"ako-operator","version":"dev","timestamp":"` +
+						time.Now().UTC().Format(time.RFC3339) + `"}`,
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		now := metav1.Now()
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-cluster",
+				Namespace:         "default",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{akoov1alpha1.ClusterFinalizer},
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: "",
+				},
+			},
+		}).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &machine.MachineReconciler{
+			Client:   fclient,
+			Log:      log.Log,
+			Scheme:   scheme,
+			Recorder: recorder,
+
+			MachinePreTerminateHookTimeout: timeout,
+		}
+		var err error
+		res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	})
+
+	When("the timeout is not set", func() {
+		BeforeEach(func() {
+			timeout = nil
+		})
+
+		It("should keep the hook while the cluster finalizer is present", func() {
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	When("the timeout is not reached", func() {
+		BeforeEach(func() {
+			t := time.Hour
+			timeout = &t
+		})
+
+		It("should keep the hook and requeue once it times out", func() {
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(res.RequeueAfter).To(BeNumerically("<=", time.Hour))
+		})
+	})
+
+	When("the hook was placed long before the Machine was deleted", func() {
+		BeforeEach(func() {
+			t := time.Hour
+			timeout = &t
+			obj.Annotations[akoov1alpha1.PreTerminateAnnotation] = `{"operator":"ako-operator","version":"dev","timestamp":"` +
+				time.Now().Add(-2*time.Hour).UTC().Format(time.RFC3339) + `"}`
+		})
+
+		It("should measure the timeout from the Machine deletion", func() {
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(recorder.Events).To(BeEmpty())
+			Expect(res.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+		})
+	})
+
+	When("the Machine is not deleted", func() {
+		BeforeEach(func() {
+			t := time.Duration(0)
+			timeout = &t
+			obj.DeletionTimestamp = nil
+			obj.Finalizers = nil
+		})
+
+		It("should keep the hook", func() {
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	When("the timeout is reached", func() {
+		BeforeEach(func() {
+			t := time.Duration(0)
+			timeout = &t
+		})
+
+		It("should remove the hook even though the cluster finalizer is present", func() {
+			Expect(obj.Annotations).NotTo(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring(akoov1alpha1.PreTerminateHookTimeoutReason))
+		})
+	})
+}
+
+func unitTestPreTerminateHookOwner() {
+	var (
+		ctx        context.Context
+		fclient    client.Client
+		reconciler *machine.MachineReconciler
+		obj        *clusterv1.Machine
+		adc        *akoov1alpha1.AKODeploymentConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "MachineSet",
+					Name:       "test-machineset",
+					UID:        "machineset-uid",
+					Controller: pointer.Bool(true),
+				}},
+			},
+			Status: clusterv1.MachineStatus{
+				Phase: string(clusterv1.MachinePhaseRunning),
+			},
+		}
+		adc = &akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-adc",
+				UID:  "adc-uid",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(akoov1alpha1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, adc, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: adc.Name,
+				},
+			},
+		}).Build()
+		reconciler = &machine.MachineReconciler{
+			Client: fclient,
+			Log:    log.Log,
+			Scheme: scheme,
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	})
+
+	It("should make the AKODeploymentConfig an owner of the hook", func() {
+		Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+		Expect(obj.OwnerReferences).To(HaveLen(2))
+		Expect(obj.OwnerReferences[1]).To(Equal(metav1.OwnerReference{
+			APIVersion: akoov1alpha1.GroupVersion.String(),
+			Kind:       akoov1alpha1.AkoDeploymentConfigKind,
+			Name:       "test-adc",
+			UID:        "adc-uid",
+		}))
+		Expect(metav1.GetControllerOf(obj).Kind).To(Equal("MachineSet"))
+
+		// reconciling again should not add it twice
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+		Expect(obj.OwnerReferences).To(HaveLen(2))
+	})
+
+	When("the machine has no controller", func() {
+		BeforeEach(func() {
+			obj.OwnerReferences = nil
+		})
+
+		It("should not add the owner reference", func() {
+			Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(obj.OwnerReferences).To(BeEmpty())
+		})
+	})
+
+	When("the AKODeploymentConfig is being deleted", func() {
+		BeforeEach(func() {
+			now := metav1.Now()
+			adc.DeletionTimestamp = &now
+			adc.Finalizers = []string{akoov1alpha1.AkoDeploymentConfigFinalizer}
+		})
+
+		It("should not add the hook", func() {
+			Expect(obj.Annotations).NotTo(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(obj.OwnerReferences).To(HaveLen(1))
+		})
+	})
+}
+
+func unitTestPreTerminateHookMachinePhase() {
+	var (
+		ctx     context.Context
+		fclient client.Client
+		obj     *clusterv1.Machine
+		res     ctrl.Result
+	)
+
+	reconcileInPhase := func(phase clusterv1.MachinePhase) {
+		ctx = context.Background()
+		obj = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+			Status: clusterv1.MachineStatus{
+				Phase: string(phase),
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels: map[string]string{
+					akoov1alpha1.AviClusterLabel: "",
+				},
+			},
+		}).Build()
+		reconciler := &machine.MachineReconciler{
+			Client: fclient,
+			Log:    log.Log,
+			Scheme: scheme,
+		}
+		var err error
+		res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	}
+
+	It("should add the hook in Running phase", func() {
+		reconcileInPhase(clusterv1.MachinePhaseRunning)
+		Expect(obj.Annotations).To(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+		Expect(res.RequeueAfter).To(BeZero())
+	})
+
+	for _, phase := range []clusterv1.MachinePhase{
+		clusterv1.MachinePhasePending,
+		clusterv1.MachinePhaseProvisioning,
+		clusterv1.MachinePhaseProvisioned,
+		clusterv1.MachinePhaseDeleting,
+		clusterv1.MachinePhaseFailed,
+		clusterv1.MachinePhaseUnknown,
+	} {
+		phase := phase
+		It(fmt.Sprintf("should not add the hook in %s phase", phase), func() {
+			reconcileInPhase(phase)
+			Expect(obj.Annotations).NotTo(HaveKey(akoov1alpha1.PreTerminateAnnotation))
+			Expect(res.RequeueAfter).To(BeZero())
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/handlers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func unitTestAviClusterLabelChangedPredicate() {
+	var (
+		fclient    client.Client
+		oldCluster *clusterv1.Cluster
+		newCluster *clusterv1.Cluster
+		queue      workqueue.RateLimitingInterface
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		machine1 := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine1",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+		}
+		fclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine1).Build()
+		oldCluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		newCluster = oldCluster.DeepCopy()
+		queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	})
+
+	AfterEach(func() {
+		queue.ShutDown()
+	})
+
+	JustBeforeEach(func() {
+		e := event.UpdateEvent{ObjectOld: oldCluster, ObjectNew: newCluster}
+		if machine.AviClusterLabelChangedPredicate().Update(e) {
+			handler.EnqueueRequestsFromMapFunc(handlers.MachinesForCluster(fclient, log.Log)).Update(e, queue)
+		}
+	})
+
+	When("only the cluster status changes", func() {
+		BeforeEach(func() {
+			newCluster.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+		})
+
+		It("should not enqueue any machine", func() {
+			Expect(queue.Len()).To(Equal(0))
+		})
+	})
+
+	When("the avi cluster label is added", func() {
+		BeforeEach(func() {
+			newCluster.Labels = map[string]string{akoov1alpha1.AviClusterLabel: ""}
+		})
+
+		It("should enqueue the machines of the cluster", func() {
+			Expect(queue.Len()).To(Equal(1))
+		})
+	})
+
+	Context("Cluster deletion predicate", func() {
+		It("should only pass the cluster update events which start the deletion or remove the finalizer", func() {
+			oldCluster.Finalizers = []string{akoov1alpha1.ClusterFinalizer}
+			deleting := oldCluster.DeepCopy()
+			now := metav1.Now()
+			deleting.DeletionTimestamp = &now
+			cleanedUp := deleting.DeepCopy()
+			cleanedUp.Finalizers = nil
+
+			p := machine.ClusterDeletionChangedPredicate()
+			Expect(p.Update(event.UpdateEvent{ObjectOld: oldCluster, ObjectNew: deleting})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: deleting, ObjectNew: cleanedUp})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: deleting, ObjectNew: deleting})).To(BeFalse())
+			provisioned := oldCluster.DeepCopy()
+			provisioned.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+			Expect(p.Update(event.UpdateEvent{ObjectOld: oldCluster, ObjectNew: provisioned})).To(BeFalse())
+		})
+	})
+}
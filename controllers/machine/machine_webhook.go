@@ -0,0 +1,56 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const validatingWebhookPath = "/validate-cluster-x-k8s-io-v1beta1-machine"
+
+//+kubebuilder:webhook:verbs=create;update,path=/validate-cluster-x-k8s-io-v1beta1-machine,mutating=false,failurePolicy=ignore,groups=cluster.x-k8s.io,resources=machines,versions=v1beta1,name=vmachine.kb.io,sideEffects=None,admissionReviewVersions=v1
+
+// MachineLabelValidator warns about the Machines created or updated without
+// the cluster label, which are skipped by the Machine reconciler. It never
+// rejects a Machine, the warnings are shown in the kubectl output.
+type MachineLabelValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &MachineLabelValidator{}
+
+// SetupWebhookWithManager registers the webhook to the webhook server of mgr
+func (v *MachineLabelValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(validatingWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *MachineLabelValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler
+func (v *MachineLabelValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj := &clusterv1.Machine{}
+	if err := v.decoder.DecodeRaw(req.Object, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	resp := admission.Allowed("")
+	if obj.Labels[clusterv1.ClusterLabelName] == "" {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf(
+			"Machine %s/%s has no %s label, it is ignored by AKO Operator",
+			req.Namespace, obj.Name, clusterv1.ClusterLabelName))
+	}
+	return resp
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package machine_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func unitTestMachineLabelValidator() {
+	var (
+		validator *machine.MachineLabelValidator
+		obj       *clusterv1.Machine
+		resp      admission.Response
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).ShouldNot(HaveOccurred())
+		validator = &machine.MachineLabelValidator{}
+		Expect(validator.InjectDecoder(decoder)).To(Succeed())
+		obj = &clusterv1.Machine{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "test-cluster",
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		raw, err := json.Marshal(obj)
+		Expect(err).ShouldNot(HaveOccurred())
+		resp = validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: obj.Namespace,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	})
+
+	When("the machine has the cluster label", func() {
+		It("should allow it without warning", func() {
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Warnings).To(BeEmpty())
+		})
+	})
+
+	When("the machine doesn't have the cluster label", func() {
+		BeforeEach(func() {
+			obj.Labels = nil
+		})
+
+		It("should allow it with a warning", func() {
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Warnings).To(ConsistOf(ContainSubstring(clusterv1.ClusterLabelName)))
+		})
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/test/builder"
@@ -28,6 +29,9 @@ var suite = builder.NewTestSuiteForController(
 
 		builder.FakeAvi = aviclient.NewFakeAviClient()
 
+		if err := controllers.SetupIndexes(mgr); err != nil {
+			return err
+		}
 		if err := (&machine.MachineReconciler{
 			Client: mgr.GetClient(),
 			Log:    ctrl.Log.WithName("controllers").WithName("Machine"),
@@ -68,4 +72,13 @@ func intgTests() {
 }
 
 func unitTests() {
+	Describe("Cluster watch predicate", unitTestAviClusterLabelChangedPredicate)
+	Describe("AKO healthy condition", unitTestAKOHealthyCondition)
+	Describe("Pre-terminate hook annotation", unitTestPreTerminateAnnotation)
+	Describe("Pre-terminate hook timeout", unitTestPreTerminateHookTimeout)
+	Describe("Pre-terminate hook owner", unitTestPreTerminateHookOwner)
+	Describe("Pre-terminate hook machine phase", unitTestPreTerminateHookMachinePhase)
+	Describe("Paused Cluster", unitTestPausedCluster)
+	Describe("Machine IP annotation", unitTestMachineIPAnnotation)
+	Describe("Machine label validating webhook", unitTestMachineLabelValidator)
 }
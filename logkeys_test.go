@@ -0,0 +1,131 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// logKeyPattern is the format of the keys passed to logr WithValues, Info and
+// Error, lowercase snake_case such as machine_name or cluster_name
+var logKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// loggerPattern matches the names the operator sources give their loggers, such
+// as log, setupLog or akoDeploymentConfigLog
+var loggerPattern = regexp.MustCompile(`(?i)log(ger)?$`)
+
+// TestVerifyLogKeys fails when a WithValues, Info or Error call of a logger of
+// the operator sources uses a key which isn't a string literal in lowercase
+// snake_case
+func TestVerifyLogKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int
+	for _, dir := range []string{".", "api", "controllers", "pkg"} {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// only the root package is checked from the root directory
+				if dir == "." && path != "." {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			calls += verifyLogKeys(t, path)
+			return nil
+		})
+		g.Expect(err).ShouldNot(HaveOccurred())
+	}
+	// the controllers' loggers must have been checked
+	g.Expect(calls).NotTo(BeZero())
+}
+
+// verifyLogKeys reports the non-conforming keys of the logger calls in the
+// file, and returns the number of calls checked
+func verifyLogKeys(t *testing.T, path string) int {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	var calls int
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// the keys follow the message of Info, and the error and the message
+		// of Error
+		var first int
+		switch sel.Sel.Name {
+		case "WithValues":
+			first = 0
+		case "Info":
+			first = 1
+		case "Error":
+			first = 2
+		default:
+			return true
+		}
+		if first > 0 && !isLogger(sel.X) {
+			return true
+		}
+		// the keys forwarded by a log sink are checked where they're passed
+		if call.Ellipsis.IsValid() {
+			return true
+		}
+		calls++
+		// the keys are every other argument, starting with the first one
+		for i := first; i < len(call.Args); i += 2 {
+			pos := fset.Position(call.Args[i].Pos())
+			lit, ok := call.Args[i].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s: log key should be a string literal", pos)
+				continue
+			}
+			key, err := strconv.Unquote(lit.Value)
+			if err != nil || !logKeyPattern.MatchString(key) {
+				t.Errorf("%s: log key %s should be lowercase snake_case", pos, lit.Value)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+// isLogger tells whether expr is a logger, either a variable or field named
+// like one, or the logger returned by its V method
+func isLogger(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return loggerPattern.MatchString(x.Name)
+	case *ast.SelectorExpr:
+		return loggerPattern.MatchString(x.Sel.Name)
+	case *ast.CallExpr:
+		if sel, ok := x.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "V" {
+			return isLogger(sel.X)
+		}
+	}
+	return false
+}
@@ -4,26 +4,45 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/apis/ako/v1alpha1"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/sync/errgroup"
 
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/util/feature"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers"
+	adccluster "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/akodeploymentconfig/cluster"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
 
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/certinjector"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/connectivity"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/debug"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/leaderelection"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/logging"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/tracing"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/version"
 	runv1alpha3 "github.com/vmware-tanzu/tanzu-framework/apis/run/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -33,14 +52,41 @@ import (
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = log.Log
+	// dynamicLogger lets the ako-operator-config ConfigMap change the log
+	// verbosity of the operator while it's running
+	dynamicLogger *logging.DynamicLogger
 )
 
+const (
+	// leaderElectionID is the leader lock of the manager running all the
+	// controllers, or all but the Machine controller with --split-manager
+	leaderElectionID = "ako-operator-leader-election"
+	// machineLeaderElectionID is the leader lock of the manager running the
+	// Machine controller with --split-manager
+	machineLeaderElectionID = "ako-operator-machine-leader-election"
+	// validatingWebhookConfigurationName and mutatingWebhookConfigurationName
+	// are the webhook configurations of AKO Operator
+	validatingWebhookConfigurationName = "ako-operator-validating-webhook-configuration"
+	mutatingWebhookConfigurationName   = "ako-operator-mutating-webhook-configuration"
+)
+
+// webhookCertDir is the directory the webhook serving certificate Secret is
+// mounted in, it's the default one of the controller-runtime webhook server
+var webhookCertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+
+// inClusterNamespacePath is the file of the namespace of the operator's service
+// account
+const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func initLog() {
 	f := func(ecfg *zapcore.EncoderConfig) {
 		ecfg.EncodeTime = zapcore.ISO8601TimeEncoder
 	}
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true),
-		zap.ConsoleEncoder(zap.EncoderConfigOption(f))))
+	// zap logs every level, the verbosity is controlled by dynamicLogger
+	dynamicLogger = logging.NewDynamicLogger(zap.New(zap.UseDevMode(true),
+		zap.ConsoleEncoder(zap.EncoderConfigOption(f)),
+		zap.Level(zapcore.Level(-logging.MaxLevel))), logging.DefaultLevel)
+	ctrl.SetLogger(dynamicLogger.Logger())
 }
 
 func init() {
@@ -57,34 +103,169 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var profilerAddress string
+	var healthProbeAddr string
+	var watchNamespaces string
+	var splitManager bool
+	var workloadClusterKubeconfigNamespace string
+	var printVersion bool
+	var leaderElectionDeadline time.Duration
+	var requeueInterval time.Duration
+	var machinePreTerminateHookTimeout time.Duration
+	var webhookDryRun bool
+	var otelEndpoint string
+	var enableDebugServer bool
+	var debugServerAddr string
+	var configNamespace string
 	flag.StringVar(&metricsAddr, "metrics-addr", "localhost:8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&healthProbeAddr, "health-probe-bind-address", ":8081", "The address the health probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&profilerAddress, "profiler-addr", "", "Bind address to expose the pprof profiler")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated list of namespaces to watch. Watch all namespaces if empty.")
+	flag.BoolVar(&splitManager, "split-manager", false, "Run the Machine controller in a separate manager with its own leader election, so it can't hold up the other controllers.")
+	flag.StringVar(&workloadClusterKubeconfigNamespace, "workload-cluster-kubeconfig-namespace", "", "Namespace of the workload cluster kubeconfig Secrets. Use the namespace of each Cluster if empty.")
+	flag.DurationVar(&leaderElectionDeadline, "leader-election-deadline", leaderelection.DefaultDeadline, "How long the in-flight reconciles are allowed to complete when the leader election lease is lost or the operator is stopped. It must be less than the lease duration minus the renew deadline, so the reconciles stop before another replica can acquire the lease.")
+	flag.DurationVar(&requeueInterval, "requeue-interval", adccluster.DefaultRequeueInterval, "How long to wait before reconciling again a Cluster where AKO isn't deployed or available yet.")
+	flag.DurationVar(&machinePreTerminateHookTimeout, "machine-pre-terminate-hook-timeout", machine.DefaultMachinePreTerminateHookTimeout, "How long a Machine deletion can be blocked by the pre-terminate hook waiting for the AVI resources cleanup, after which the hook is removed anyway. The hook is never force removed if 0.")
+	flag.BoolVar(&webhookDryRun, "webhook-dry-run", false, "Allow the requests to the mutating webhook without changing the objects, and preview the changes as a JSON merge patch in a warning and the "+akoov1alpha1.MutationPreviewAuditAnnotation+" audit annotation of the responses.")
+	flag.StringVar(&otelEndpoint, "otel-endpoint", "", "The host:port of the OTLP/HTTP collector the reconcile traces are exported to, or its http:// URL for a plain HTTP collector. Tracing is disabled if empty.")
+	flag.BoolVar(&enableDebugServer, "enable-debug-server", false, "Serve the heap, goroutine and active reconcile statistics as JSON on "+debug.StatsPath+" of --debug-server-addr.")
+	flag.StringVar(&debugServerAddr, "debug-server-addr", "localhost:8082", "The address the debug server binds to when --enable-debug-server is set.")
+	flag.StringVar(&configNamespace, "config-namespace", "", "Namespace of the "+logging.ConfigMapName+" ConfigMap, whose "+logging.LogLevelKey+" key sets the log verbosity to info, debug or a number. Use the namespace the operator runs in if empty.")
+	flag.BoolVar(&printVersion, "version", false, "Print the version and exit.")
+	flag.Func("feature-gates", "A set of key=value pairs that describe feature gates for alpha/experimental features. Options are:\n"+
+		strings.Join(feature.DefaultMutableFeatureGate.KnownFeatures(), "\n"), feature.DefaultMutableFeatureGate.Set)
 	flag.Parse()
 
+	if printVersion {
+		fmt.Println(version.String())
+		os.Exit(0)
+	}
+
 	if profilerAddress != "" {
 		setupLog.Info(
 			"Profiler listening for requests",
-			"profiler-addr", profilerAddress)
+			"profiler_addr", profilerAddress)
 		go runProfiler(profilerAddress)
 	}
-	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		Port:               9443,
+	if enableDebugServer {
+		setupLog.Info("Debug server listening for requests", "debug_server_addr", debugServerAddr)
+		go runDebugServer(debugServerAddr)
+	}
+	go metrics.UpdateActiveGoroutines(context.Background(), metrics.ActiveGoroutinesUpdateInterval)
+	shutdownTracing := func(context.Context) error { return nil }
+	if otelEndpoint != "" {
+		var err error
+		if shutdownTracing, err = tracing.Setup(context.Background(), otelEndpoint); err != nil {
+			setupLog.Error(err, "unable to set up the OTLP trace exporter")
+			os.Exit(1)
+		}
+		setupLog.Info("Exporting traces", "otel_endpoint", otelEndpoint)
+	}
+	cfg := config.GetConfigOrDie()
+	leaseDuration, renewDeadline := leaderelection.DefaultLeaseDuration, leaderelection.DefaultRenewDeadline
+	if err := leaderelection.ValidateDeadline(leaderElectionDeadline, leaseDuration, renewDeadline); err != nil {
+		setupLog.Error(err, "invalid --leader-election-deadline")
+		os.Exit(1)
+	}
+	leaderelection.DefaultTracker.Deadline = leaderElectionDeadline
+	if machinePreTerminateHookTimeout < 0 {
+		setupLog.Error(fmt.Errorf("%s is negative", machinePreTerminateHookTimeout), "invalid --machine-pre-terminate-hook-timeout")
+		os.Exit(1)
+	}
+	options := manager.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: healthProbeAddr,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       leaderElectionID,
+		LeaseDuration:          &leaseDuration,
+		RenewDeadline:          &renewDeadline,
+		NewCache:               newCacheFunc(watchNamespaces),
 		ClientDisableCacheFor: []client.Object{
 			&corev1.ConfigMap{},
 			&corev1.Secret{},
 		},
-	})
+		// keep the lease until the operator exits, so the new leader can't
+		// take over while the in-flight reconciles complete
+		LeaderElectionReleaseOnCancel: false,
+		GracefulShutdownTimeout:       &leaderElectionDeadline,
+		// the webhook server watches its serving certificate in CertDir and
+		// reloads it when it's rotated, no restart is needed
+		Port:    9443,
+		CertDir: webhookCertDir,
+	}
+	mgr, err := manager.New(cfg, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	mgrs := []manager.Manager{mgr}
+	reconcilerOpts := controllers.Options{
+		WorkloadClusterKubeconfigNamespace: workloadClusterKubeconfigNamespace,
+		RequeueInterval:                    requeueInterval,
+		MachinePreTerminateHookTimeout:     machinePreTerminateHookTimeout,
+	}
 
-	err = controllers.SetupReconcilers(mgr)
+	if err = mgr.Add(connectivity.NewChecker(mgr.GetClient(),
+		ctrl.Log.WithName("connectivity"), connectivity.DefaultCheckInterval)); err != nil {
+		setupLog.Error(err, "unable to set up AVI controller connectivity checker")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&certinjector.CertificateInjector{
+		Client:                          mgr.GetClient(),
+		Reader:                          mgr.GetAPIReader(),
+		RESTMapper:                      mgr.GetRESTMapper(),
+		Log:                             ctrl.Log.WithName("certinjector"),
+		CertDir:                         webhookCertDir,
+		Interval:                        certinjector.DefaultInterval,
+		ValidatingWebhookConfigurations: []string{validatingWebhookConfigurationName},
+		MutatingWebhookConfigurations:   []string{mutatingWebhookConfigurationName},
+	}); err != nil {
+		setupLog.Error(err, "unable to set up webhook CA injector")
+		os.Exit(1)
+	}
+	if configNamespace == "" {
+		configNamespace, err = inClusterNamespace()
+	}
 	if err != nil {
+		setupLog.Info("Unable to find the operator namespace, the log level can't be changed", "reason", err.Error())
+	} else if err = mgr.Add(&logging.ConfigMapWatcher{
+		Config:    cfg,
+		Scheme:    scheme,
+		Log:       ctrl.Log.WithName("logging"),
+		Logger:    dynamicLogger,
+		Namespace: configNamespace,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up log level watcher")
+		os.Exit(1)
+	}
+	if err = setupHealthChecks(mgr); err != nil {
+		setupLog.Error(err, "unable to set up health checks")
+		os.Exit(1)
+	}
+
+	if splitManager {
+		machineMgr, err := manager.New(cfg, machineManagerOptions(options))
+		if err != nil {
+			setupLog.Error(err, "unable to start machine manager")
+			os.Exit(1)
+		}
+		for _, m := range []manager.Manager{mgr, machineMgr} {
+			if err = controllers.SetupIndexes(m); err != nil {
+				setupLog.Error(err, "Unable to setup field indexes")
+				os.Exit(1)
+			}
+		}
+		if err = controllers.SetupMachineReconciler(machineMgr, reconcilerOpts); err != nil {
+			setupLog.Error(err, "Unable to setup machine reconciler")
+			os.Exit(1)
+		}
+		if err = controllers.SetupAKODeploymentConfigReconcilers(mgr, reconcilerOpts); err != nil {
+			setupLog.Error(err, "Unable to setup reconcilers")
+			os.Exit(1)
+		}
+		mgrs = append(mgrs, machineMgr)
+	} else if err = controllers.SetupReconcilers(mgr, reconcilerOpts); err != nil {
 		setupLog.Error(err, "Unable to setup reconcilers")
 		os.Exit(1)
 	}
@@ -92,18 +273,92 @@ func main() {
 	printRunningEnv()
 
 	//setup webhook here
-	if err = (&akoov1alpha1.AKODeploymentConfig{}).SetupWebhookWithManager(mgr); err != nil {
+	if err = (&akoov1alpha1.AKODeploymentConfigWebhook{DryRun: webhookDryRun}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "AKODeploymentConfig")
 		os.Exit(1)
 	}
+	if err = (&machine.MachineLabelValidator{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Machine")
+		os.Exit(1)
+	}
 
-	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	setupLog.Info("starting manager", "managers", len(mgrs))
+	err = startManagers(ctrl.SetupSignalHandler(), mgrs...)
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush the traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
+		// the managers don't wait for the reconciles when the lease is lost
+		if !leaderelection.DefaultTracker.Wait() {
+			setupLog.Info("In-flight reconciles didn't complete before the deadline", "deadline", leaderElectionDeadline.String())
+		}
 		os.Exit(1)
 	}
 }
 
+// machineManagerOptions returns the options of the manager running the
+// Machine controller with --split-manager. It shares the scheme and the
+// cache settings of the main manager, but has its own leader lock and
+// doesn't serve metrics, health probes or webhooks, which are served by
+// the main manager
+func machineManagerOptions(options manager.Options) manager.Options {
+	options.LeaderElectionID = machineLeaderElectionID
+	options.MetricsBindAddress = "0"
+	options.HealthProbeBindAddress = "0"
+	return options
+}
+
+// startManagers starts the managers in separate goroutines and blocks until
+// they all stop. When a manager fails the others are stopped too, so the
+// operator restarts as a whole
+func startManagers(ctx context.Context, mgrs ...manager.Manager) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, mgr := range mgrs {
+		mgr := mgr
+		g.Go(func() error {
+			return mgr.Start(ctx)
+		})
+	}
+	return g.Wait()
+}
+
+// newCacheFunc returns a cache builder restricted to the given comma-separated
+// namespaces, or nil to let the manager watch all namespaces. Cluster-scoped
+// objects like AKODeploymentConfig are always watched.
+func newCacheFunc(watchNamespaces string) cache.NewCacheFunc {
+	var namespaces []string
+	for _, ns := range strings.Split(watchNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+	setupLog.Info("Watching namespaces", "namespaces", namespaces)
+	return cache.MultiNamespacedCacheBuilder(namespaces)
+}
+
+// inClusterNamespace returns the namespace the operator runs in, from its
+// service account
+func inClusterNamespace() (string, error) {
+	namespace, err := os.ReadFile(inClusterNamespacePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
+
+// setupHealthChecks registers the liveness and readiness checks served on the
+// health probe bind address
+func setupHealthChecks(mgr manager.Manager) error {
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		return err
+	}
+	return mgr.AddReadyzCheck("readyz", healthz.Ping)
+}
+
 func runProfiler(addr string) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -117,7 +372,16 @@ func runProfiler(addr string) {
 	}
 }
 
+func runDebugServer(addr string) {
+	err := http.ListenAndServe(addr, debug.NewServeMux(leaderelection.DefaultTracker.Active))
+	if err != nil {
+		setupLog.Error(err, "unable to start listening")
+	}
+}
+
 func printRunningEnv() {
+	setupLog.Info("AKO Operator build", "version", version.Version, "git_commit", version.GitCommit, "build_date", version.BuildDate)
+
 	if ako_operator.IsBootStrapCluster() {
 		setupLog.Info("AKO Operator Running in Bootstrap Kind Cluster")
 	} else {
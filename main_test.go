@@ -0,0 +1,128 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func TestHealthProbeBindAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	// reserve a free port to use as the custom health probe address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	healthProbeAddr := listener.Addr().String()
+	g.Expect(listener.Close()).To(Succeed())
+
+	mgr, err := manager.New(&rest.Config{Host: "http://127.0.0.1:1"}, manager.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: healthProbeAddr,
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(setupHealthChecks(mgr)).To(Succeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = mgr.Start(ctx)
+	}()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		g.Eventually(func() int {
+			resp, err := http.Get("http://" + healthProbeAddr + path)
+			if err != nil {
+				return 0
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}, 10*time.Second, 100*time.Millisecond).Should(Equal(http.StatusOK))
+	}
+}
+
+func TestNewCacheFunc(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(newCacheFunc("")).To(BeNil())
+	g.Expect(newCacheFunc(" , ")).To(BeNil())
+
+	newCache := newCacheFunc("watched-a, watched-b")
+	g.Expect(newCache).NotTo(BeNil())
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{clusterv1.GroupVersion})
+	mapper.Add(clusterv1.GroupVersion.WithKind("Cluster"), meta.RESTScopeNamespace)
+	c, err := newCache(&rest.Config{Host: "http://127.0.0.1:1"}, cache.Options{
+		Scheme: scheme,
+		Mapper: mapper,
+	})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	// objects outside of the watched namespaces are never served by the
+	// cache, so they can't be reconciled
+	err = c.Get(context.Background(), client.ObjectKey{Namespace: "unwatched", Name: "test-cluster"}, &clusterv1.Cluster{})
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("unknown namespace for the cache"))
+}
+
+func TestStartManagers(t *testing.T) {
+	g := NewWithT(t)
+
+	options := manager.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: "0",
+		LeaderElectionID:       leaderElectionID,
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	}
+	machineOptions := machineManagerOptions(options)
+	g.Expect(machineOptions.LeaderElectionID).To(Equal(machineLeaderElectionID))
+	g.Expect(machineOptions.LeaderElectionID).NotTo(Equal(options.LeaderElectionID))
+	g.Expect(machineOptions.Scheme).To(BeIdenticalTo(options.Scheme))
+
+	var mgrs []manager.Manager
+	started := make(chan struct{}, 2)
+	for _, opts := range []manager.Options{options, machineOptions} {
+		mgr, err := manager.New(&rest.Config{Host: "http://127.0.0.1:1"}, opts)
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}))).To(Succeed())
+		mgrs = append(mgrs, mgr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- startManagers(ctx, mgrs...)
+	}()
+
+	// both managers are running
+	for range mgrs {
+		g.Eventually(started, 10*time.Second).Should(Receive())
+	}
+	cancel()
+	g.Eventually(errs, 10*time.Second).Should(Receive(BeNil()))
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// defaultPriorityClassName is the priority class of the operator pods, so they
+// aren't evicted before the workloads of resource-constrained clusters
+const defaultPriorityClassName = "system-cluster-critical"
+
+// TestManagerPriorityClassName fails when the operator Deployment of the
+// manifests doesn't run with the default priority class
+func TestManagerPriorityClassName(t *testing.T) {
+	for _, file := range []string{
+		filepath.Join("config", "manager", "manager.yaml"),
+		filepath.Join("config", "ytt", "static.yaml"),
+	} {
+		t.Run(file, func(t *testing.T) {
+			g := NewWithT(t)
+			deployments := loadDeployments(g, file)
+			g.Expect(deployments).To(HaveLen(1))
+			g.Expect(deployments[0].Spec.Template.Spec.PriorityClassName).To(Equal(defaultPriorityClassName))
+		})
+	}
+
+	// the ytt template of the Deployment takes it from its data values
+	t.Run("ytt data values", func(t *testing.T) {
+		g := NewWithT(t)
+		f, err := os.Open(filepath.Join("config", "ytt", "values.yaml"))
+		g.Expect(err).ShouldNot(HaveOccurred())
+		defer f.Close()
+		// the data values follow the empty document of the #@data/values annotation
+		values := map[string]interface{}{}
+		decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+		for len(values) == 0 {
+			g.Expect(decoder.Decode(&values)).To(Succeed())
+		}
+		g.Expect(values).To(HaveKeyWithValue("priorityClassName", defaultPriorityClassName))
+	})
+}
+
+// loadDeployments returns the Deployments defined in the file
+func loadDeployments(g *WithT, file string) []appsv1.Deployment {
+	f, err := os.Open(file)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer f.Close()
+
+	var deployments []appsv1.Deployment
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		deployment := appsv1.Deployment{}
+		if err := decoder.Decode(&deployment); err != nil {
+			if errors.Is(err, io.EOF) {
+				return deployments
+			}
+			g.Expect(err).ShouldNot(HaveOccurred())
+		}
+		if deployment.Kind == "Deployment" {
+			deployments = append(deployments, deployment)
+		}
+	}
+}
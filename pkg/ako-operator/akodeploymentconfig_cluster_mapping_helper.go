@@ -5,9 +5,11 @@ package ako_operator
 
 import (
 	"context"
+	"sort"
 
 	"github.com/go-logr/logr"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -33,6 +35,47 @@ func ListAkoDeploymentConfigSelectClusters(
 	}...); err != nil {
 		return nil, err
 	}
+	// clusters created from a ClusterClass referencing this akodeploymentconfig,
+	// and clusters labeled by users with this akodeploymentconfig are selected as well
+	if !selector.Empty() {
+		classClusters, err := listClusterClassSelectClusters(ctx, kclient, obj.Name)
+		if err != nil {
+			return nil, err
+		}
+		var labelClusters clusterv1.ClusterList
+		if err := kclient.List(ctx, &labelClusters, client.MatchingLabels{
+			akoov1alpha1.AKODeploymentConfigClusterLabel: obj.Name,
+		}); err != nil {
+			return nil, err
+		}
+		selected := make(map[string]bool)
+		for _, c := range clusters.Items {
+			selected[c.Namespace+"/"+c.Name] = true
+		}
+		for _, c := range labelClusters.Items {
+			if !selected[c.Namespace+"/"+c.Name] {
+				selected[c.Namespace+"/"+c.Name] = true
+				clusters.Items = append(clusters.Items, c)
+			}
+		}
+		// the selectors of other akodeploymentconfig objects win over the
+		// ClusterClass, same as in GetAKODeploymentConfigForCluster
+		for i := range classClusters {
+			c := classClusters[i]
+			if selected[c.Namespace+"/"+c.Name] {
+				continue
+			}
+			matched, _, err := matchAKODeploymentConfigSelectors(ctx, kclient, log, &c)
+			if err != nil {
+				return nil, err
+			}
+			if matched != nil {
+				continue
+			}
+			selected[c.Namespace+"/"+c.Name] = true
+			clusters.Items = append(clusters.Items, c)
+		}
+	}
 	// remove clusters that:
 	// 1. not ready
 	// 2. management cluster
@@ -49,8 +92,9 @@ func ListAkoDeploymentConfigSelectClusters(
 			}
 			adcName, exist := cluster.Labels[akoov1alpha1.AviClusterLabel]
 			// if cluster is already selected by other customized adc objects, skip
-			// only clusters selected by default adc with empty selector object can be overrided
-			if exist && adcName != obj.Name {
+			// only clusters selected by default adc with empty selector object,
+			// or labeled by users with this adc can be overrided
+			if exist && adcName != obj.Name && cluster.Labels[akoov1alpha1.AKODeploymentConfigClusterLabel] != obj.Name {
 				if !isDefaultWcADC(adcName) || !defaultADCHasEmptySelector(ctx, kclient) {
 					continue
 				}
@@ -62,6 +106,40 @@ func ListAkoDeploymentConfigSelectClusters(
 	return &clusters, kerrors.NewAggregate(allErrs)
 }
 
+// AKODeploymentConfigClusterSelectorIndex is the field index of the
+// akodeploymentconfig objects by the "key=value" pairs of the matchLabels of
+// their cluster selector
+const AKODeploymentConfigClusterSelectorIndex = "spec.clusterSelector.matchLabels"
+
+// anyClusterIndexValue indexes the akodeploymentconfig objects without
+// matchLabels in their cluster selector, they may select any cluster
+const anyClusterIndexValue = "*"
+
+// IndexAKODeploymentConfigs registers the akodeploymentconfig field indexes
+// GetAKODeploymentConfigForCluster looks up, it must be called once for each
+// manager whose client is used to call it
+func IndexAKODeploymentConfigs(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &akoov1alpha1.AKODeploymentConfig{},
+		AKODeploymentConfigClusterSelectorIndex, AKODeploymentConfigClusterSelectorIndexFunc)
+}
+
+// AKODeploymentConfigClusterSelectorIndexFunc returns the values of an
+// akodeploymentconfig object in the AKODeploymentConfigClusterSelectorIndex
+func AKODeploymentConfigClusterSelectorIndexFunc(o client.Object) []string {
+	adc, ok := o.(*akoov1alpha1.AKODeploymentConfig)
+	if !ok {
+		return nil
+	}
+	if len(adc.Spec.ClusterSelector.MatchLabels) == 0 {
+		return []string{anyClusterIndexValue}
+	}
+	values := make([]string, 0, len(adc.Spec.ClusterSelector.MatchLabels))
+	for k, v := range adc.Spec.ClusterSelector.MatchLabels {
+		values = append(values, k+"="+v)
+	}
+	return values
+}
+
 // GetAKODeploymentConfigForCluster return the akodeloymentconfig object which selects
 // current cluster
 func GetAKODeploymentConfigForCluster(
@@ -69,15 +147,67 @@ func GetAKODeploymentConfigForCluster(
 	kclient client.Client,
 	log logr.Logger,
 	cluster *clusterv1.Cluster) (*akoov1alpha1.AKODeploymentConfig, error) {
-	// list all the akodeploymentconfig objects
-	var akoDeploymentConfigs akoov1alpha1.AKODeploymentConfigList
-	if err := kclient.List(ctx, &akoDeploymentConfigs, []client.ListOption{}...); err != nil {
-		log.Error(err, "Failed to list all AKODeploymentConfig objects")
+	// the akodeploymentconfig the cluster is labeled with by users wins, the
+	// avi label is set by the operator itself, so it can't be used here as it
+	// would keep the cluster selected after its selector labels are removed
+	if adcName := cluster.Labels[akoov1alpha1.AKODeploymentConfigClusterLabel]; adcName != "" {
+		adc, err := getAKODeploymentConfig(ctx, kclient, adcName)
+		if err != nil {
+			log.Error(err, "Failed to get AKODeploymentConfig", "adc", adcName)
+			return nil, err
+		}
+		if adc != nil {
+			log.Info("cluster is selected by the akodeploymentconfig named in its label", "adc", adcName)
+			return adc, nil
+		}
+	}
+	matched, defaultAdc, err := matchAKODeploymentConfigSelectors(ctx, kclient, log, cluster)
+	if err != nil {
 		return nil, err
 	}
-	// find which adc matches current cluster
-	var defaultAdc akoov1alpha1.AKODeploymentConfig
-	for _, akoDeploymentConfig := range akoDeploymentConfigs.Items {
+	if matched != nil {
+		log.Info("cluster is selected by akodeploymentconfig", "adc", matched.Name)
+		return matched, nil
+	}
+	// then the akodeploymentconfig referenced by the cluster's ClusterClass
+	if adcName := GetClusterClassDefaultADCName(ctx, kclient, cluster); adcName != "" {
+		adc, err := getAKODeploymentConfig(ctx, kclient, adcName)
+		if err != nil {
+			log.Error(err, "Failed to get AKODeploymentConfig", "adc", adcName)
+			return nil, err
+		}
+		if adc != nil {
+			log.Info("cluster is selected by its ClusterClass default akodeploymentconfig", "adc", adcName)
+			return adc, nil
+		}
+	}
+	// only default adc with empty selector can select all clusters and return
+	if defaultAdc != nil {
+		log.Info("cluster is selected by akodeploymentconfig", "adc", defaultAdc.Name)
+		return defaultAdc, nil
+	}
+	log.Info("cluster is not selected by any akodeploymentconfig objects")
+	return nil, nil
+}
+
+// matchAKODeploymentConfigSelectors returns the first akodeploymentconfig
+// object whose non-empty selector matches the cluster, and the default one if
+// its selector is empty
+func matchAKODeploymentConfigSelectors(
+	ctx context.Context,
+	kclient client.Client,
+	log logr.Logger,
+	cluster *clusterv1.Cluster) (matched, defaultAdc *akoov1alpha1.AKODeploymentConfig, err error) {
+	// only the akodeploymentconfig objects the index matches may select the
+	// cluster, the selectors are still checked as the index only covers
+	// matchLabels
+	candidates, err := listAKODeploymentConfigCandidates(ctx, kclient, cluster)
+	if err != nil {
+		log.Error(err, "Failed to list AKODeploymentConfig objects")
+		return nil, nil, err
+	}
+	for i := range candidates {
+		akoDeploymentConfig := &candidates[i]
 		if selector, err := metav1.LabelSelectorAsSelector(&akoDeploymentConfig.Spec.ClusterSelector); err != nil {
 			log.Error(err, "Failed to convert label sector to selector")
 		} else if selector.Empty() {
@@ -85,17 +215,99 @@ func GetAKODeploymentConfigForCluster(
 				defaultAdc = akoDeploymentConfig
 			}
 		} else if selector.Matches(labels.Set(cluster.GetLabels())) {
-			log.Info("cluster is selected by akodeploymentconfig", "adc", akoDeploymentConfig.Name)
-			return &akoDeploymentConfig, nil
+			return akoDeploymentConfig, defaultAdc, nil
 		}
 	}
-	// only default adc with empty selector can select all clusters and return
-	if defaultAdc.Name == akoov1alpha1.WorkloadClusterAkoDeploymentConfig {
-		log.Info("cluster is selected by akodeploymentconfig", "adc", defaultAdc.Name)
-		return &defaultAdc, nil
+	return nil, defaultAdc, nil
+}
+
+// getAKODeploymentConfig gets the akodeploymentconfig object by name, nil if
+// it doesn't exist
+func getAKODeploymentConfig(ctx context.Context, kclient client.Client, name string) (*akoov1alpha1.AKODeploymentConfig, error) {
+	adc := &akoov1alpha1.AKODeploymentConfig{}
+	if err := kclient.Get(ctx, client.ObjectKey{Name: name}, adc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	log.Info("cluster is not selected by any akodeploymentconfig objects")
-	return nil, nil
+	return adc, nil
+}
+
+// listAKODeploymentConfigCandidates lists the akodeploymentconfig objects
+// which may select the cluster through the AKODeploymentConfigClusterSelectorIndex,
+// sorted by name
+func listAKODeploymentConfigCandidates(ctx context.Context, kclient client.Client, cluster *clusterv1.Cluster) ([]akoov1alpha1.AKODeploymentConfig, error) {
+	values := []string{anyClusterIndexValue}
+	for k, v := range cluster.GetLabels() {
+		values = append(values, k+"="+v)
+	}
+	candidates := make(map[string]akoov1alpha1.AKODeploymentConfig)
+	for _, value := range values {
+		var akoDeploymentConfigs akoov1alpha1.AKODeploymentConfigList
+		if err := kclient.List(ctx, &akoDeploymentConfigs, client.MatchingFields{
+			AKODeploymentConfigClusterSelectorIndex: value,
+		}); err != nil {
+			return nil, err
+		}
+		for _, adc := range akoDeploymentConfigs.Items {
+			candidates[adc.Name] = adc
+		}
+	}
+	res := make([]akoov1alpha1.AKODeploymentConfig, 0, len(candidates))
+	for _, adc := range candidates {
+		res = append(res, adc)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// GetClusterClassDefaultADCName returns the name of the akodeploymentconfig referenced by
+// the ClusterClass current cluster is created from, empty if there is none
+func GetClusterClassDefaultADCName(ctx context.Context, kclient client.Client, cluster *clusterv1.Cluster) string {
+	if !IsClusterClassBasedCluster(cluster) || cluster.Spec.Topology.Class == "" {
+		return ""
+	}
+	var clusterClass clusterv1.ClusterClass
+	if err := kclient.Get(ctx, client.ObjectKey{
+		Name:      cluster.Spec.Topology.Class,
+		Namespace: cluster.Namespace,
+	}, &clusterClass); err != nil {
+		return ""
+	}
+	return clusterClass.Annotations[akoov1alpha1.ClusterClassDefaultADCAnnotation]
+}
+
+// listClusterClassSelectClusters lists all clusters created from a ClusterClass which
+// references the akodeploymentconfig adcName
+func listClusterClassSelectClusters(ctx context.Context, kclient client.Client, adcName string) ([]clusterv1.Cluster, error) {
+	var clusterClasses clusterv1.ClusterClassList
+	if err := kclient.List(ctx, &clusterClasses); err != nil {
+		return nil, err
+	}
+	// namespace/name of the ClusterClasses referencing this akodeploymentconfig
+	classes := make(map[string]bool)
+	for _, cc := range clusterClasses.Items {
+		if cc.Annotations[akoov1alpha1.ClusterClassDefaultADCAnnotation] == adcName {
+			classes[cc.Namespace+"/"+cc.Name] = true
+		}
+	}
+	if len(classes) == 0 {
+		return nil, nil
+	}
+	var clusters clusterv1.ClusterList
+	if err := kclient.List(ctx, &clusters); err != nil {
+		return nil, err
+	}
+	var res []clusterv1.Cluster
+	for _, cluster := range clusters.Items {
+		if IsClusterClassBasedCluster(&cluster) && classes[cluster.Namespace+"/"+cluster.Spec.Topology.Class] {
+			res = append(res, cluster)
+		}
+	}
+	return res, nil
 }
 
 // SkipCluster checks if akodeploymentconfig controller should skip reconciling this cluster or not
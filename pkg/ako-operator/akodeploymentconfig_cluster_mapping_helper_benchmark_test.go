@@ -0,0 +1,79 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package ako_operator
+
+import (
+	"fmt"
+	"testing"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BenchmarkAKODeploymentConfigClusterLookup compares finding the
+// akodeploymentconfig selecting a cluster among 100 objects in an informer
+// indexer, which backs the manager's cache, with and without the
+// AKODeploymentConfigClusterSelectorIndex
+func BenchmarkAKODeploymentConfigClusterLookup(b *testing.B) {
+	indexer := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{
+		AKODeploymentConfigClusterSelectorIndex: func(obj interface{}) ([]string, error) {
+			return AKODeploymentConfigClusterSelectorIndexFunc(obj.(client.Object)), nil
+		},
+	})
+	for i := 0; i < 100; i++ {
+		if err := indexer.Add(&akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("adc-%d", i)},
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"avi-profile": fmt.Sprintf("profile-%d", i)},
+				},
+			},
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	clusterLabels := labels.Set{"avi-profile": "profile-99", "env": "production"}
+	selects := func(objs []interface{}) bool {
+		for _, obj := range objs {
+			adc := obj.(*akoov1alpha1.AKODeploymentConfig)
+			selector, err := metav1.LabelSelectorAsSelector(&adc.Spec.ClusterSelector)
+			if err == nil && !selector.Empty() && selector.Matches(clusterLabels) {
+				return true
+			}
+		}
+		return false
+	}
+
+	b.Run("unindexed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !selects(indexer.List()) {
+				b.Fatal("no akodeploymentconfig selects the cluster")
+			}
+		}
+	})
+
+	b.Run("indexed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			candidates, err := indexer.ByIndex(AKODeploymentConfigClusterSelectorIndex, anyClusterIndexValue)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for k, v := range clusterLabels {
+				objs, err := indexer.ByIndex(AKODeploymentConfigClusterSelectorIndex, k+"="+v)
+				if err != nil {
+					b.Fatal(err)
+				}
+				candidates = append(candidates, objs...)
+			}
+			if !selects(candidates) {
+				b.Fatal("no akodeploymentconfig selects the cluster")
+			}
+		}
+	})
+}
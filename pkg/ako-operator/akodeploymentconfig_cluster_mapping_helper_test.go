@@ -0,0 +1,209 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package ako_operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("AKODeploymentConfig cluster mapping", func() {
+	var (
+		ctx        context.Context
+		kclient    client.Client
+		cluster    *clusterv1.Cluster
+		defaultADC *akoov1alpha1.AKODeploymentConfig
+		namedADC   *akoov1alpha1.AKODeploymentConfig
+		extraObjs  []client.Object
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		defaultADC = &akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: akoov1alpha1.WorkloadClusterAkoDeploymentConfig},
+		}
+		namedADC = &akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "production-avi-config"},
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"avi-profile": "production"},
+				},
+			},
+		}
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				Labels:    map[string]string{},
+			},
+		}
+		extraObjs = nil
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(akoov1alpha1.AddToScheme(scheme)).To(Succeed())
+		kclient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(extraObjs, cluster, defaultADC, namedADC)...).Build()
+	})
+
+	When("the cluster avi label has no value", func() {
+		BeforeEach(func() {
+			cluster.Labels[akoov1alpha1.AviClusterLabel] = ""
+		})
+
+		It("should fall back to the default akodeploymentconfig", func() {
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal(akoov1alpha1.WorkloadClusterAkoDeploymentConfig))
+		})
+	})
+
+	When("the cluster is labeled with an akodeploymentconfig", func() {
+		BeforeEach(func() {
+			cluster.Labels[akoov1alpha1.AKODeploymentConfigClusterLabel] = "production-avi-config"
+		})
+
+		It("should use the named akodeploymentconfig", func() {
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal("production-avi-config"))
+		})
+
+		It("should be selected by the named akodeploymentconfig", func() {
+			clusters, err := ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, namedADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(HaveLen(1))
+			Expect(clusters.Items[0].Name).To(Equal("test-cluster"))
+		})
+
+		It("should not be selected twice when it also matches the selector", func() {
+			cluster.Labels["avi-profile"] = "production"
+			Expect(kclient.Update(ctx, cluster)).To(Succeed())
+			clusters, err := ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, namedADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(HaveLen(1))
+		})
+	})
+
+	When("only the selector labels of the cluster are removed", func() {
+		BeforeEach(func() {
+			// set by the operator when the cluster was selected
+			cluster.Labels[akoov1alpha1.AviClusterLabel] = "production-avi-config"
+		})
+
+		It("should not be selected by the akodeploymentconfig in its avi label anymore", func() {
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal(akoov1alpha1.WorkloadClusterAkoDeploymentConfig))
+
+			clusters, err := ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, namedADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(BeEmpty())
+		})
+	})
+
+	When("the cluster is labeled with an akodeploymentconfig that doesn't exist", func() {
+		BeforeEach(func() {
+			cluster.Labels[akoov1alpha1.AKODeploymentConfigClusterLabel] = "non-existent"
+		})
+
+		It("should fall back to the default akodeploymentconfig", func() {
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal(akoov1alpha1.WorkloadClusterAkoDeploymentConfig))
+		})
+	})
+
+	When("the cluster is created from a ClusterClass referencing an akodeploymentconfig", func() {
+		var classADC *akoov1alpha1.AKODeploymentConfig
+
+		BeforeEach(func() {
+			classADC = &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "class-avi-config"},
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					ClusterSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"avi-profile": "class"},
+					},
+				},
+			}
+			extraObjs = []client.Object{classADC, &clusterv1.ClusterClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-class",
+					Namespace: "default",
+					Annotations: map[string]string{
+						akoov1alpha1.ClusterClassDefaultADCAnnotation: classADC.Name,
+					},
+				},
+			}}
+			cluster.Spec.Topology = &clusterv1.Topology{Class: "test-class"}
+		})
+
+		It("should be selected by the ClusterClass akodeploymentconfig", func() {
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal(classADC.Name))
+
+			clusters, err := ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, classADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(HaveLen(1))
+		})
+
+		It("should be selected by the akodeploymentconfig whose selector also matches it", func() {
+			cluster.Labels["avi-profile"] = "production"
+			Expect(kclient.Update(ctx, cluster)).To(Succeed())
+
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal(namedADC.Name))
+
+			clusters, err := ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, namedADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(HaveLen(1))
+			clusters, err = ListAkoDeploymentConfigSelectClusters(ctx, kclient, log.Log, classADC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(clusters.Items).To(BeEmpty())
+		})
+	})
+
+	Context("cluster selector index", func() {
+		It("should index the akodeploymentconfig by its matchLabels", func() {
+			Expect(AKODeploymentConfigClusterSelectorIndexFunc(namedADC)).To(ConsistOf("avi-profile=production"))
+		})
+
+		It("should index the akodeploymentconfig without matchLabels for any cluster", func() {
+			Expect(AKODeploymentConfigClusterSelectorIndexFunc(defaultADC)).To(ConsistOf(anyClusterIndexValue))
+			namedADC.Spec.ClusterSelector = metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "avi-profile",
+					Operator: metav1.LabelSelectorOpExists,
+				}},
+			}
+			Expect(AKODeploymentConfigClusterSelectorIndexFunc(namedADC)).To(ConsistOf(anyClusterIndexValue))
+		})
+
+		It("should select the akodeploymentconfig matching the cluster labels", func() {
+			cluster.Labels["avi-profile"] = "production"
+			adc, err := GetAKODeploymentConfigForCluster(ctx, kclient, log.Log, cluster)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(adc).NotTo(BeNil())
+			Expect(adc.Name).To(Equal("production-avi-config"))
+		})
+	})
+})
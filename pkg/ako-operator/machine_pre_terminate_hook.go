@@ -0,0 +1,69 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package ako_operator
+
+import (
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// SetPreTerminateHookOwner adds a non-controller owner reference to the
+// AKODeploymentConfig on a Machine with the pre-terminate hook, so the hooks
+// placed for an AKODeploymentConfig can be found and removed when it's
+// deleted. Machines without a controller are skipped, the AKODeploymentConfig
+// would otherwise be their only owner and they would be garbage collected
+// along with it. It returns whether the owner reference is set.
+func SetPreTerminateHookOwner(machine *clusterv1.Machine, adc *akoov1alpha1.AKODeploymentConfig) bool {
+	if metav1.GetControllerOf(machine) == nil {
+		return false
+	}
+	ownerRefs := machine.GetOwnerReferences()
+	for _, ref := range ownerRefs {
+		if ref.UID == adc.UID {
+			return true
+		}
+	}
+	machine.SetOwnerReferences(append(ownerRefs, metav1.OwnerReference{
+		APIVersion: akoov1alpha1.GroupVersion.String(),
+		Kind:       akoov1alpha1.AkoDeploymentConfigKind,
+		Name:       adc.Name,
+		UID:        adc.UID,
+	}))
+	return true
+}
+
+// RemovePreTerminateHook removes the pre-terminate hook from a Machine along
+// with its owner references to AKODeploymentConfigs. It returns whether the
+// Machine is changed.
+func RemovePreTerminateHook(machine *clusterv1.Machine) bool {
+	_, changed := machine.Annotations[akoov1alpha1.PreTerminateAnnotation]
+	delete(machine.Annotations, akoov1alpha1.PreTerminateAnnotation)
+
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range machine.GetOwnerReferences() {
+		if isAKODeploymentConfigRef(ref) {
+			changed = true
+			continue
+		}
+		ownerRefs = append(ownerRefs, ref)
+	}
+	machine.SetOwnerReferences(ownerRefs)
+	return changed
+}
+
+// IsPreTerminateHookOwner returns whether the AKODeploymentConfig is an owner
+// of the pre-terminate hook of the Machine
+func IsPreTerminateHookOwner(machine *clusterv1.Machine, adc *akoov1alpha1.AKODeploymentConfig) bool {
+	for _, ref := range machine.GetOwnerReferences() {
+		if isAKODeploymentConfigRef(ref) && ref.UID == adc.UID {
+			return true
+		}
+	}
+	return false
+}
+
+func isAKODeploymentConfigRef(ref metav1.OwnerReference) bool {
+	return ref.APIVersion == akoov1alpha1.GroupVersion.String() && ref.Kind == akoov1alpha1.AkoDeploymentConfigKind
+}
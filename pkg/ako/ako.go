@@ -9,7 +9,6 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
-	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	appv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,12 +22,14 @@ const (
 	akoCleanUpTimeoutStatus    = "Timeout"
 )
 
-func CleanupFinished(ctx context.Context, remoteClient client.Client, log logr.Logger) (bool, error) {
+// CleanupFinished checks whether AKO deployed in the namespace has finished
+// cleaning up the AVI resources
+func CleanupFinished(ctx context.Context, remoteClient client.Client, namespace string, log logr.Logger) (bool, error) {
 
 	ss := &appv1.StatefulSet{}
 	if err := remoteClient.Get(ctx, client.ObjectKey{
 		Name:      akoStatefulSetName,
-		Namespace: akoov1alpha1.AviNamespace,
+		Namespace: namespace,
 	}, ss); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("AKO Statefulset is gone, consider it as a signal as deletion has finished")
@@ -51,7 +51,7 @@ var _ = Describe("AKO", func() {
 		if createSS {
 			Expect(fclient.Create(ctx, ss)).ToNot(HaveOccurred())
 		}
-		finished, err = CleanupFinished(ctx, fclient, logger)
+		finished, err = CleanupFinished(ctx, fclient, akoov1alpha1.AviNamespace, logger)
 	})
 
 	When("StatefulSet does not exist", func() {
@@ -12,10 +12,12 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/apiserver/pkg/util/feature"
 
 	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/features"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -48,10 +50,10 @@ func NewValues(obj *akoov1alpha1.AKODeploymentConfig, clusterNameSpacedName stri
 	nodePortSelector := NewNodePortSelector(&obj.Spec.ExtraConfigs.NodePortSelector)
 	rbac := NewRbac(obj.Spec.ExtraConfigs.Rbac)
 
-	return &Values{
+	values := &Values{
 		LoadBalancerAndIngressService: LoadBalancerAndIngressService{
 			Name:      "ako-" + clusterNameSpacedName,
-			Namespace: akoov1alpha1.AviNamespace,
+			Namespace: obj.GetAKONamespace(),
 			Config: Config{
 				IsClusterService:      "",
 				ReplicaCount:          1,
@@ -67,7 +69,11 @@ func NewValues(obj *akoov1alpha1.AKODeploymentConfig, clusterNameSpacedName stri
 				LogFile:               obj.Spec.ExtraConfigs.Log.LogFile,
 			},
 		},
-	}, nil
+	}
+	if obj.Spec.ExtraConfigs.Replicas != nil {
+		values.LoadBalancerAndIngressService.Config.ReplicaCount = int(*obj.Spec.ExtraConfigs.Replicas)
+	}
+	return values, nil
 }
 
 // NewValuesFromBytes unmarshalls a byte array
@@ -283,6 +289,9 @@ func NewNetworkSettings(obj *akoov1alpha1.AKODeploymentConfig) (*NetworkSettings
 
 	settings.NodeNetworkList = obj.Spec.ExtraConfigs.IngressConfigs.NodeNetworkList
 	settings.VIPNetworkList = []v1alpha1.VIPNetwork{{NetworkName: obj.Spec.DataNetwork.Name, CIDR: obj.Spec.DataNetwork.CIDR}}
+	if vipConfig := obj.Spec.ExtraConfigs.VIPConfig; vipConfig.VIPNetworkName != "" {
+		settings.VIPNetworkList = []v1alpha1.VIPNetwork{{NetworkName: vipConfig.VIPNetworkName, CIDR: vipConfig.VIPNetworkCIDR}}
+	}
 
 	if len(settings.NodeNetworkList) != 0 {
 		jsonBytes, err := json.Marshal(settings.NodeNetworkList)
@@ -307,12 +316,15 @@ func NewNetworkSettings(obj *akoov1alpha1.AKODeploymentConfig) (*NetworkSettings
 		settings.ControlPlaneNetworkCIDR = obj.Spec.DataNetwork.CIDR
 	}
 
+	if feature.DefaultFeatureGate.Enabled(features.MultiVRF) && obj.Spec.ExtraConfigs.NetworksConfig.NsxtT1LR != "" {
+		settings.NsxtT1LR = obj.Spec.ExtraConfigs.NetworksConfig.NsxtT1LR
+	}
+	if !feature.DefaultFeatureGate.Enabled(features.BGPSupport) {
+		return settings, nil
+	}
 	if obj.Spec.ExtraConfigs.NetworksConfig.EnableRHI != nil {
 		settings.EnableRHI = strconv.FormatBool(*obj.Spec.ExtraConfigs.NetworksConfig.EnableRHI)
 	}
-	if obj.Spec.ExtraConfigs.NetworksConfig.NsxtT1LR != "" {
-		settings.NsxtT1LR = obj.Spec.ExtraConfigs.NetworksConfig.NsxtT1LR
-	}
 	settings.BGPPeerLabels = obj.Spec.ExtraConfigs.NetworksConfig.BGPPeerLabels
 	if len(settings.BGPPeerLabels) != 0 {
 		jsonBytes, err := json.Marshal(settings.BGPPeerLabels)
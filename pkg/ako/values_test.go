@@ -7,11 +7,14 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/component-base/featuregate"
 	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/features"
 )
 
 var _ = Describe("AKO", func() {
@@ -139,4 +142,158 @@ var _ = Describe("AKO", func() {
 			})
 		})
 	})
+
+	Context("Rendered values", func() {
+		for _, tc := range []struct {
+			name           string
+			customize      func(adc *akoov1alpha1.AKODeploymentConfig)
+			expectedValues []string
+		}{
+			{
+				name: "the auto fqdn is default",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.L4Configs = akoov1alpha1.AKOL4Config{DefaultDomain: "example.com", AutoFQDN: "default"}
+				},
+				expectedValues: []string{"auto_fqdn: default", "default_domain: example.com"},
+			},
+			{
+				name: "the auto fqdn is flat",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.L4Configs = akoov1alpha1.AKOL4Config{DefaultDomain: "example.com", AutoFQDN: "flat"}
+				},
+				expectedValues: []string{"auto_fqdn: flat", "default_domain: example.com"},
+			},
+			{
+				name: "the auto fqdn is disabled",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.L4Configs = akoov1alpha1.AKOL4Config{DefaultDomain: "example.com", AutoFQDN: "disabled"}
+				},
+				expectedValues: []string{"auto_fqdn: disabled", "default_domain: example.com"},
+			},
+			{
+				name: "a custom vip network is set",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.VIPConfig = akoov1alpha1.VIPConfig{
+						VIPNetworkName: "vip-network",
+						VIPNetworkCIDR: "10.1.0.0/24",
+					}
+				},
+				expectedValues: []string{
+					`vip_network_list: '[{"networkName":"vip-network","cidr":"10.1.0.0/24"}]'`,
+					"network_name: test-akdc",
+				},
+			},
+			{
+				name:           "a custom vip network is not set",
+				expectedValues: []string{`vip_network_list: '[{"networkName":"test-akdc","cidr":"10.0.0.0/24"}]'`},
+			},
+			{
+				name: "the log level is set",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.Log.LogLevel = "DEBUG"
+				},
+				expectedValues: []string{"log_level: DEBUG"},
+			},
+			{
+				name:           "the log level is not set",
+				expectedValues: []string{"log_level: INFO"},
+			},
+			{
+				name: "the replicas is set",
+				customize: func(adc *akoov1alpha1.AKODeploymentConfig) {
+					adc.Spec.ExtraConfigs.Replicas = pointer.Int32(2)
+				},
+				expectedValues: []string{"replica_count: 2"},
+			},
+			{
+				name:           "the replicas is not set",
+				expectedValues: []string{"replica_count: 1"},
+			},
+		} {
+			tc := tc
+			When(tc.name, func() {
+				It("should render the expected values", func() {
+					akoDeploymentConfig := newTestAKODeploymentConfig()
+					if tc.customize != nil {
+						tc.customize(akoDeploymentConfig)
+					}
+					values, err := NewValues(akoDeploymentConfig, "test")
+					Expect(err).ShouldNot(HaveOccurred())
+					output, err := values.YttYaml(nil)
+					Expect(err).ShouldNot(HaveOccurred())
+					for _, expected := range tc.expectedValues {
+						Expect(output).To(ContainSubstring(expected))
+					}
+				})
+			})
+		}
+	})
+
+	Context("Feature gates", func() {
+		var (
+			akoDeploymentConfig *akoov1alpha1.AKODeploymentConfig
+			settings            *NetworkSettings
+			gates               map[featuregate.Feature]bool
+		)
+		BeforeEach(func() {
+			akoDeploymentConfig = newTestAKODeploymentConfig()
+			akoDeploymentConfig.Spec.ExtraConfigs.NetworksConfig = akoov1alpha1.NetworksConfig{
+				EnableRHI:     pointer.BoolPtr(true),
+				BGPPeerLabels: []string{"peer1"},
+				NsxtT1LR:      "/infra/tier-1s/cluster-t1",
+			}
+			gates = map[featuregate.Feature]bool{}
+		})
+		JustBeforeEach(func() {
+			for f, enabled := range gates {
+				Expect(feature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(f): enabled})).To(Succeed())
+			}
+			var err error
+			settings, err = NewNetworkSettings(akoDeploymentConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			// restore the default of the gates
+			for f := range gates {
+				Expect(feature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(f): true})).To(Succeed())
+			}
+		})
+		It("should render the BGP and Tier1 settings by default", func() {
+			Expect(settings.EnableRHI).To(Equal("true"))
+			Expect(settings.BGPPeerLabelsJson).To(Equal(`["peer1"]`))
+			Expect(settings.NsxtT1LR).To(Equal("/infra/tier-1s/cluster-t1"))
+		})
+		When("BGPSupport is disabled", func() {
+			BeforeEach(func() {
+				gates[features.BGPSupport] = false
+			})
+			It("should not render the BGP settings", func() {
+				Expect(settings.EnableRHI).To(BeEmpty())
+				Expect(settings.BGPPeerLabelsJson).To(BeEmpty())
+				Expect(settings.NsxtT1LR).To(Equal("/infra/tier-1s/cluster-t1"))
+			})
+		})
+		When("MultiVRF is disabled", func() {
+			BeforeEach(func() {
+				gates[features.MultiVRF] = false
+			})
+			It("should not render the Tier1 logical router", func() {
+				Expect(settings.NsxtT1LR).To(BeEmpty())
+				Expect(settings.EnableRHI).To(Equal("true"))
+			})
+		})
+	})
 })
+
+// newTestAKODeploymentConfig returns the AKODeploymentConfig the rendering of
+// the AKO values is tested with
+func newTestAKODeploymentConfig() *akoov1alpha1.AKODeploymentConfig {
+	return &akoov1alpha1.AKODeploymentConfig{
+		Spec: akoov1alpha1.AKODeploymentConfigSpec{
+			DataNetwork: akoov1alpha1.DataNetwork{
+				Name: "test-akdc",
+				CIDR: "10.0.0.0/24",
+			},
+		},
+	}
+}
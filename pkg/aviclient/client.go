@@ -10,8 +10,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -47,18 +50,37 @@ type AviClientConfig struct {
 
 var ErrEmptyInput = errors.New("input is empty")
 
+// ParseControllerAddress splits the address of an AVI controller, which is an
+// IP address or a hostname optionally followed by a port, e.g.
+// avi.example.com:9443. The port is empty when the address doesn't have one,
+// the HTTPS default port is used then.
+func ParseControllerAddress(address string) (host, port string, err error) {
+	host, port, err = net.SplitHostPort(address)
+	if err != nil {
+		// no port, or a bare IPv6 address
+		if addrErr, ok := err.(*net.AddrError); ok && (addrErr.Err == "missing port in address" || addrErr.Err == "too many colons in address") {
+			return address, "", nil
+		}
+		return "", "", err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", errors.Errorf("invalid port %q, it should be a number between 1 and 65535", port)
+	}
+	return host, port, nil
+}
+
 // NewAviClientFromSecrets creates a Client from two secrets, adminCredential and CA
 func NewAviClientFromSecrets(c client.Client, ctx context.Context, log logr.Logger,
 	controllerIP, credName, credNamespace, caName, caNamespace, version string) (*realAviClient, error) {
 	if controllerIP == "" {
-		log.Error(ErrEmptyInput, "controllerIP is empty", "controllerIP", controllerIP)
+		log.Error(ErrEmptyInput, "controllerIP is empty", "controller_ip", controllerIP)
 		return nil, ErrEmptyInput
 	}
 
 	if credName == "" || credNamespace == "" || caName == "" || caNamespace == "" {
-		log.Error(ErrEmptyInput, "empty secret", "credName",
-			credName, "credNamespace", credNamespace,
-			"caName", caName, "caNamespace", caNamespace)
+		log.Error(ErrEmptyInput, "empty secret", "cred_name",
+			credName, "cred_namespace", credNamespace,
+			"ca_name", caName, "ca_namespace", caNamespace)
 		return nil, ErrEmptyInput
 	}
 
@@ -103,6 +125,12 @@ func NewAviClientFromSecrets(c client.Client, ctx context.Context, log logr.Logg
 
 // NewAviClient creates an Client
 func NewAviClient(config *AviClientConfig, version string) (*realAviClient, error) {
+	// the AVI session connects to https://<ServerIP>/, so a port in the
+	// address is used as is
+	if _, _, err := ParseControllerAddress(config.ServerIP); err != nil {
+		return nil, err
+	}
+
 	// Initialize transport
 	var transport *http.Transport
 	if config.CA != "" {
@@ -175,6 +203,16 @@ func IsAviUserNonExistentError(err error) bool {
 	return err == nil && matched
 }
 
+// IsAviTenantNonExistentError returns if an error is Tenant doesn't exist error
+// by matching error message
+func IsAviTenantNonExistentError(err error) bool {
+	if err == nil {
+		return false
+	}
+	matched, err := regexp.Match(`No object of type tenant with name .*is found`, []byte(err.Error()))
+	return err == nil && matched
+}
+
 // IsAviRoleNonExistentError returns if an error is User role doesn't exist error
 // by matching error message
 func IsAviRoleNonExistentError(err error) bool {
@@ -189,6 +227,99 @@ func (r *realAviClient) GetControllerVersion() (string, error) {
 	return r.AviSession.GetControllerVersion()
 }
 
+// GetClusterVersion returns the version reported by the AVI controller's
+// cluster/version API
+func (r *realAviClient) GetClusterVersion() (string, error) {
+	var resp struct {
+		Version string `json:"Version"`
+	}
+	if err := r.AviSession.Get("api/cluster/version", &resp); err != nil {
+		return "", err
+	}
+	if resp.Version == "" {
+		return "", errors.New("empty version in AVI controller cluster/version response")
+	}
+	return resp.Version, nil
+}
+
+// poolInventoryPageSize is the number of pools fetched per page of the
+// pool-inventory API
+const poolInventoryPageSize = 200
+
+// poolInventory is the part of a pool-inventory API result
+// VirtualServiceOperStatesByServerIP needs
+type poolInventory struct {
+	Config struct {
+		Servers []struct {
+			IP struct {
+				Addr string `json:"addr"`
+			} `json:"ip"`
+		} `json:"servers"`
+	} `json:"config"`
+	VirtualServices []string `json:"virtualservices"`
+}
+
+// VirtualServiceOperStatesByServerIP returns the operational state (e.g.
+// OPER_UP) of the virtual services whose pools have the given ip as a server,
+// keyed by virtual service name
+func (r *realAviClient) VirtualServiceOperStatesByServerIP(ip string) (map[string]string, error) {
+	var pools []poolInventory
+	for page := 1; ; page++ {
+		res, err := r.AviSession.GetCollectionRaw(fmt.Sprintf("api/pool-inventory?include_name&page_size=%d&page=%d", poolInventoryPageSize, page))
+		if err != nil {
+			return nil, err
+		}
+		var results []poolInventory
+		if err := json.Unmarshal(res.Results, &results); err != nil {
+			return nil, err
+		}
+		pools = append(pools, results...)
+		if res.Next == "" || len(results) == 0 || len(pools) >= res.Count {
+			break
+		}
+	}
+
+	states := map[string]string{}
+	// a virtual service of several pools of the ip is fetched once
+	fetched := map[string]bool{}
+	for _, pool := range pools {
+		hasServer := false
+		for _, server := range pool.Config.Servers {
+			if server.IP.Addr == ip {
+				hasServer = true
+				break
+			}
+		}
+		if !hasServer {
+			continue
+		}
+		for _, ref := range pool.VirtualServices {
+			// refs look like https://<controller>/api/virtualservice/<uuid>#<name>
+			uuid := strings.Split(ref, "#")[0]
+			uuid = uuid[strings.LastIndex(uuid, "/")+1:]
+			if fetched[uuid] {
+				continue
+			}
+			fetched[uuid] = true
+			var vs struct {
+				Config struct {
+					Name string `json:"name"`
+				} `json:"config"`
+				Runtime struct {
+					OperStatus struct {
+						State string `json:"state"`
+					} `json:"oper_status"`
+				} `json:"runtime"`
+			}
+			if err := r.AviSession.Get("api/virtualservice-inventory/"+uuid, &vs); err != nil {
+				return nil, err
+			}
+			states[vs.Config.Name] = vs.Runtime.OperStatus.State
+		}
+	}
+	return states, nil
+}
+
 func (r *realAviClient) GetObjectByName(obj string, name string, cloudName string, result interface{}, options ...session.ApiOptionsParams) error {
 	uri := "/api/" + obj + "/?include_name&name=" + name + "&cloud_ref.name=" + cloudName
 	res, err := r.AviSession.GetCollectionRaw(uri, options...)
@@ -268,6 +399,10 @@ func (r *realAviClient) TenantGet(uuid string, options ...session.ApiOptionsPara
 	return r.Tenant.Get(uuid)
 }
 
+func (r *realAviClient) TenantGetByName(name string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
+	return r.Tenant.GetByName(name)
+}
+
 func (r *realAviClient) RoleGetByName(name string, options ...session.ApiOptionsParams) (*models.Role, error) {
 	return r.Role.GetByName(name)
 }
@@ -284,6 +419,10 @@ func (r *realAviClient) PoolGetByName(name string, options ...session.ApiOptions
 	return r.Pool.GetByName(name)
 }
 
+func (r *realAviClient) IPAddrGroupGetByName(name string, options ...session.ApiOptionsParams) (*models.IPAddrGroup, error) {
+	return r.IPAddrGroup.GetByName(name)
+}
+
 func (r *realAviClient) AviCertificateConfig() (string, error) {
 	return r.config.CA, nil
 }
@@ -21,6 +21,7 @@ type FakeAviClient struct {
 	Role                   *RoleClient
 	VirtualService         *VirtualServiceClient
 	Pool                   *PoolClient
+	IPAddrGroup            *IPAddrGroupClient
 }
 
 func NewFakeAviClient() *FakeAviClient {
@@ -32,6 +33,8 @@ func NewFakeAviClient() *FakeAviClient {
 		User:                   &UserClient{},
 		Tenant:                 &TenantClient{},
 		Role:                   &RoleClient{},
+		VirtualService:         &VirtualServiceClient{},
+		IPAddrGroup:            &IPAddrGroupClient{},
 	}
 }
 
@@ -108,6 +111,10 @@ func (r *FakeAviClient) TenantGet(uuid string, options ...session.ApiOptionsPara
 	return r.Tenant.Get(uuid)
 }
 
+func (r *FakeAviClient) TenantGetByName(name string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
+	return r.Tenant.GetByName(name)
+}
+
 func (r *FakeAviClient) RoleGetByName(name string, options ...session.ApiOptionsParams) (*models.Role, error) {
 	return r.Role.GetByName(name)
 }
@@ -120,10 +127,18 @@ func (r *FakeAviClient) VirtualServiceGetByName(name string, options ...session.
 	return r.VirtualService.GetByName(name)
 }
 
+func (r *FakeAviClient) VirtualServiceOperStatesByServerIP(ip string) (map[string]string, error) {
+	return r.VirtualService.OperStatesByServerIP(ip)
+}
+
 func (r *FakeAviClient) PoolGetByName(name string, options ...session.ApiOptionsParams) (*models.Pool, error) {
 	return r.Pool.GetByName(name)
 }
 
+func (r *FakeAviClient) IPAddrGroupGetByName(name string, options ...session.ApiOptionsParams) (*models.IPAddrGroup, error) {
+	return r.IPAddrGroup.GetByName(name)
+}
+
 func (r *FakeAviClient) AviCertificateConfig() (string, error) {
 	return "", nil
 }
@@ -132,6 +147,10 @@ func (r *FakeAviClient) GetControllerVersion() (string, error) {
 	return "", nil
 }
 
+func (r *FakeAviClient) GetClusterVersion() (string, error) {
+	return r.GetControllerVersion()
+}
+
 // ServiceEngineGroup Client
 type ServiceEngineGroupClient struct {
 	getByNameFn GetByNameSEGFunc
@@ -259,19 +278,32 @@ func (client *UserClient) Update(obj *models.User, options ...session.ApiOptions
 
 // Tenant Client
 type TenantClient struct {
-	getTenantFn GetTenantFunc
+	getTenantFn       GetTenantFunc
+	getByNameTenantFn GetByNameTenantFunc
 }
 
 type GetTenantFunc func(uuid string, options ...session.ApiOptionsParams) (*models.Tenant, error)
+type GetByNameTenantFunc func(name string, options ...session.ApiOptionsParams) (*models.Tenant, error)
 
 func (client *TenantClient) SetGetTenantFunc(fn GetTenantFunc) {
 	client.getTenantFn = fn
 }
 
+func (client *TenantClient) SetGetByNameTenantFunc(fn GetByNameTenantFunc) {
+	client.getByNameTenantFn = fn
+}
+
 func (client *TenantClient) Get(uuid string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
 	return client.getTenantFn(uuid)
 }
 
+func (client *TenantClient) GetByName(name string, options ...session.ApiOptionsParams) (*models.Tenant, error) {
+	if client.getByNameTenantFn == nil {
+		return nil, errors.New("can't find tenant")
+	}
+	return client.getByNameTenantFn(name)
+}
+
 // Role Client
 type RoleClient struct {
 	getByNameRoleFn GetByNameRoleFunc
@@ -314,10 +346,12 @@ func (client *PoolClient) GetByName(name string, options ...session.ApiOptionsPa
 
 // VirtualService Client
 type VirtualServiceClient struct {
-	getByNameFn GetByNameVSFunc
+	getByNameFn            GetByNameVSFunc
+	operStatesByServerIPFn OperStatesByServerIPVSFunc
 }
 
 type GetByNameVSFunc func(name string, options ...session.ApiOptionsParams) (*models.VirtualService, error)
+type OperStatesByServerIPVSFunc func(ip string) (map[string]string, error)
 
 func (client *VirtualServiceClient) SetGetByNameFn(fn GetByNameVSFunc) {
 	client.getByNameFn = fn
@@ -326,3 +360,32 @@ func (client *VirtualServiceClient) SetGetByNameFn(fn GetByNameVSFunc) {
 func (client *VirtualServiceClient) GetByName(name string, options ...session.ApiOptionsParams) (*models.VirtualService, error) {
 	return client.getByNameFn(name)
 }
+
+func (client *VirtualServiceClient) SetOperStatesByServerIPFn(fn OperStatesByServerIPVSFunc) {
+	client.operStatesByServerIPFn = fn
+}
+
+func (client *VirtualServiceClient) OperStatesByServerIP(ip string) (map[string]string, error) {
+	if client.operStatesByServerIPFn == nil {
+		return map[string]string{}, nil
+	}
+	return client.operStatesByServerIPFn(ip)
+}
+
+// IPAddrGroup Client
+type IPAddrGroupClient struct {
+	getByNameFn GetByNameIPAddrGroupFunc
+}
+
+type GetByNameIPAddrGroupFunc func(name string, options ...session.ApiOptionsParams) (*models.IPAddrGroup, error)
+
+func (client *IPAddrGroupClient) SetGetByNameFn(fn GetByNameIPAddrGroupFunc) {
+	client.getByNameFn = fn
+}
+
+func (client *IPAddrGroupClient) GetByName(name string, options ...session.ApiOptionsParams) (*models.IPAddrGroup, error) {
+	if client.getByNameFn == nil {
+		return nil, errors.New("can't find ip address group")
+	}
+	return client.getByNameFn(name)
+}
@@ -24,6 +24,7 @@ type Client interface {
 	UserUpdate(obj *models.User, options ...session.ApiOptionsParams) (*models.User, error)
 
 	TenantGet(uuid string, options ...session.ApiOptionsParams) (*models.Tenant, error)
+	TenantGetByName(name string, options ...session.ApiOptionsParams) (*models.Tenant, error)
 
 	RoleGetByName(name string, options ...session.ApiOptionsParams) (*models.Role, error)
 	RoleCreate(obj *models.Role, options ...session.ApiOptionsParams) (*models.Role, error)
@@ -32,10 +33,15 @@ type Client interface {
 	IPAMDNSProviderProfileUpdate(obj *models.IPAMDNSProviderProfile, options ...session.ApiOptionsParams) (*models.IPAMDNSProviderProfile, error)
 
 	VirtualServiceGetByName(name string, options ...session.ApiOptionsParams) (*models.VirtualService, error)
+	VirtualServiceOperStatesByServerIP(ip string) (map[string]string, error)
 
 	PoolGetByName(name string, options ...session.ApiOptionsParams) (*models.Pool, error)
 
+	IPAddrGroupGetByName(name string, options ...session.ApiOptionsParams) (*models.IPAddrGroup, error)
+
 	AviCertificateConfig() (string, error)
 
 	GetControllerVersion() (string, error)
+
+	GetClusterVersion() (string, error)
 }
@@ -0,0 +1,199 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package certinjector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CAFileName is the file of the serving certificate Secret holding the
+	// CA which signed the certificate
+	CAFileName = "ca.crt"
+	// CertFileName is the file of the serving certificate Secret holding the
+	// certificate, it's used as CA when CAFileName is missing since a
+	// self-signed certificate is its own CA
+	CertFileName = "tls.crt"
+
+	// DefaultInterval is how often the CA is injected again, the kubelet
+	// updates the serving certificate in CertDir when its Secret changes
+	DefaultInterval = time.Minute
+)
+
+// certManagerCertificate is the kind of the cert-manager Certificates, the
+// cainjector of cert-manager fills the caBundle of the webhook configurations
+// annotated with cert-manager.io/inject-ca-from when it's served
+var certManagerCertificate = schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get;patch
+
+// CertificateInjector injects the CA of the webhook serving certificate into
+// the caBundle of the webhook configurations of AKO Operator at startup, then
+// every Interval so the caBundle follows the rotations of the serving
+// certificate Secret. It only does so when cert-manager isn't installed, in
+// which case the serving certificate Secret is provisioned by other means and
+// nothing else injects its CA.
+type CertificateInjector struct {
+	// Client patches the webhook configurations
+	Client client.Client
+	// Reader gets the webhook configurations, it's meant to be the API
+	// reader of the manager so the webhook configurations aren't cached
+	Reader client.Reader
+	// RESTMapper tells whether cert-manager is installed
+	RESTMapper meta.RESTMapper
+	Log        logr.Logger
+
+	// CertDir is the directory of the webhook serving certificate
+	CertDir string
+	// Interval is how often the CA is injected again, it's only injected at
+	// startup when it's zero
+	Interval time.Duration
+	// ValidatingWebhookConfigurations and MutatingWebhookConfigurations are
+	// the names of the webhook configurations to inject the CA into, the
+	// missing ones are skipped
+	ValidatingWebhookConfigurations []string
+	MutatingWebhookConfigurations   []string
+}
+
+// Start injects the CA, then injects it again every Interval until the
+// context is done. It implements manager.Runnable.
+func (i *CertificateInjector) Start(ctx context.Context) error {
+	installed, err := i.certManagerInstalled()
+	if err != nil {
+		return err
+	}
+	if installed {
+		i.Log.Info("cert-manager is installed, skip injecting the webhook CA")
+		return nil
+	}
+	if err := i.Inject(ctx); err != nil {
+		return err
+	}
+	if i.Interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(i.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// the webhook configurations are only patched when the CA changed
+			if err := i.Inject(ctx); err != nil {
+				i.Log.Error(err, "Failed to inject the webhook CA again")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica inject the CA, since the webhook
+// server runs in all of them and injecting is idempotent
+func (i *CertificateInjector) NeedLeaderElection() bool {
+	return false
+}
+
+// Inject sets the caBundle of every webhook of the webhook configurations to
+// the CA in CertDir
+func (i *CertificateInjector) Inject(ctx context.Context) error {
+	caBundle, err := i.readCABundle()
+	if err != nil {
+		return err
+	}
+	for _, name := range i.ValidatingWebhookConfigurations {
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := i.inject(ctx, name, config, caBundle, func() []*admissionregistrationv1.WebhookClientConfig {
+			clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, 0, len(config.Webhooks))
+			for j := range config.Webhooks {
+				clientConfigs = append(clientConfigs, &config.Webhooks[j].ClientConfig)
+			}
+			return clientConfigs
+		}); err != nil {
+			return err
+		}
+	}
+	for _, name := range i.MutatingWebhookConfigurations {
+		config := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		if err := i.inject(ctx, name, config, caBundle, func() []*admissionregistrationv1.WebhookClientConfig {
+			clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, 0, len(config.Webhooks))
+			for j := range config.Webhooks {
+				clientConfigs = append(clientConfigs, &config.Webhooks[j].ClientConfig)
+			}
+			return clientConfigs
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inject gets the webhook configuration into config, and patches it when one
+// of the client configs returned by clientConfigs doesn't have caBundle as CA
+func (i *CertificateInjector) inject(ctx context.Context, name string, config client.Object, caBundle []byte, clientConfigs func() []*admissionregistrationv1.WebhookClientConfig) error {
+	log := i.Log.WithValues("webhook_configuration_name", name)
+	if err := i.Reader.Get(ctx, client.ObjectKey{Name: name}, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Webhook configuration not found, skip injecting the CA")
+			return nil
+		}
+		return err
+	}
+	patch := client.MergeFrom(config.DeepCopyObject().(client.Object))
+	changed := false
+	for _, clientConfig := range clientConfigs() {
+		if !bytes.Equal(clientConfig.CABundle, caBundle) {
+			clientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := i.Client.Patch(ctx, config, patch); err != nil {
+		return err
+	}
+	log.Info("Injected the webhook CA")
+	return nil
+}
+
+// readCABundle reads the CA of the serving certificate in CertDir
+func (i *CertificateInjector) readCABundle() ([]byte, error) {
+	caBundle, err := os.ReadFile(filepath.Join(i.CertDir, CAFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		caBundle, err = os.ReadFile(filepath.Join(i.CertDir, CertFileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(caBundle)) == 0 {
+		return nil, fmt.Errorf("no webhook CA in %s", i.CertDir)
+	}
+	return caBundle, nil
+}
+
+// certManagerInstalled returns whether the cert-manager Certificate kind is
+// served by the API server
+func (i *CertificateInjector) certManagerInstalled() (bool, error) {
+	if _, err := i.RESTMapper.RESTMapping(certManagerCertificate); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
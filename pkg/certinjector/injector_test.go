@@ -0,0 +1,202 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package certinjector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// writeServingCert writes a self-signed serving certificate for 127.0.0.1
+// in dir, with its CA in ca.crt when withCA is true
+func writeServingCert(g *WithT, dir string, withCA bool) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "webhook-service"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	g.Expect(os.WriteFile(filepath.Join(dir, CertFileName), certPEM, 0600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0600)).To(Succeed())
+	if withCA {
+		g.Expect(os.WriteFile(filepath.Join(dir, CAFileName), certPEM, 0600)).To(Succeed())
+	}
+	return certPEM, keyPEM
+}
+
+func webhookConfigurations() []client.Object {
+	return []client.Object{
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "validating"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "vakodeploymentconfig.kb.io"},
+				{Name: "vmachine.kb.io"},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "mutating"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "makodeploymentconfig.kb.io"},
+			},
+		},
+	}
+}
+
+func newInjector(c client.Client, mapper meta.RESTMapper, certDir string) *CertificateInjector {
+	return &CertificateInjector{
+		Client:                          c,
+		Reader:                          c,
+		RESTMapper:                      mapper,
+		Log:                             log.Log,
+		CertDir:                         certDir,
+		ValidatingWebhookConfigurations: []string{"validating", "missing"},
+		MutatingWebhookConfigurations:   []string{"mutating"},
+	}
+}
+
+func TestCertificateInjector(t *testing.T) {
+	t.Run("cert-manager not installed", func(t *testing.T) {
+		g := NewWithT(t)
+		certDir := t.TempDir()
+		certPEM, keyPEM := writeServingCert(g, certDir, true)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfigurations()...).Build()
+
+		injector := newInjector(c, meta.NewDefaultRESTMapper(nil), certDir)
+		g.Expect(injector.Start(context.Background())).To(Succeed())
+
+		validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "validating"}, validating)).To(Succeed())
+		g.Expect(validating.Webhooks).To(HaveLen(2))
+		for _, webhook := range validating.Webhooks {
+			g.Expect(webhook.ClientConfig.CABundle).To(Equal(certPEM))
+		}
+		mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "mutating"}, mutating)).To(Succeed())
+		g.Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal(certPEM))
+
+		// the API server trusts the webhook server with the injected CA
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		g.Expect(err).ShouldNot(HaveOccurred())
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+		server.StartTLS()
+		defer server.Close()
+
+		pool := x509.NewCertPool()
+		g.Expect(pool.AppendCertsFromPEM(validating.Webhooks[0].ClientConfig.CABundle)).To(BeTrue())
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+		resp, err := httpClient.Get(server.URL)
+		g.Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	t.Run("serving certificate without CA", func(t *testing.T) {
+		g := NewWithT(t)
+		certDir := t.TempDir()
+		certPEM, _ := writeServingCert(g, certDir, false)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfigurations()...).Build()
+
+		injector := newInjector(c, meta.NewDefaultRESTMapper(nil), certDir)
+		g.Expect(injector.Start(context.Background())).To(Succeed())
+
+		mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "mutating"}, mutating)).To(Succeed())
+		g.Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal(certPEM))
+	})
+
+	t.Run("serving certificate rotated", func(t *testing.T) {
+		g := NewWithT(t)
+		certDir := t.TempDir()
+		certPEM, _ := writeServingCert(g, certDir, true)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfigurations()...).Build()
+
+		injector := newInjector(c, meta.NewDefaultRESTMapper(nil), certDir)
+		injector.Interval = 10 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- injector.Start(ctx)
+		}()
+
+		caBundle := func() []byte {
+			mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+			g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "mutating"}, mutating)).To(Succeed())
+			return mutating.Webhooks[0].ClientConfig.CABundle
+		}
+		g.Eventually(caBundle, time.Second).Should(Equal(certPEM))
+
+		rotatedPEM, _ := writeServingCert(g, certDir, true)
+		g.Expect(rotatedPEM).NotTo(Equal(certPEM))
+		g.Eventually(caBundle, time.Second).Should(Equal(rotatedPEM))
+
+		cancel()
+		g.Eventually(done, time.Second).Should(Receive(BeNil()))
+	})
+
+	t.Run("cert-manager installed", func(t *testing.T) {
+		g := NewWithT(t)
+		certDir := t.TempDir()
+		writeServingCert(g, certDir, true)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfigurations()...).Build()
+		certManagerV1 := schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{certManagerV1})
+		mapper.Add(certManagerV1.WithKind("Certificate"), meta.RESTScopeNamespace)
+
+		injector := newInjector(c, mapper, certDir)
+		g.Expect(injector.Start(context.Background())).To(Succeed())
+
+		validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "validating"}, validating)).To(Succeed())
+		g.Expect(validating.Webhooks[0].ClientConfig.CABundle).To(BeEmpty())
+	})
+
+	t.Run("no serving certificate", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfigurations()...).Build()
+
+		injector := newInjector(c, meta.NewDefaultRESTMapper(nil), t.TempDir())
+		g.Expect(injector.Start(context.Background())).NotTo(Succeed())
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package connectivity
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/aviclient"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCheckInterval is how often the AVI controller connectivity is
+// re-checked
+const DefaultCheckInterval = time.Minute
+
+// CheckFunc checks whether the AVI controller of the given
+// AKODeploymentConfig is reachable
+type CheckFunc func(ctx context.Context, c client.Client, log logr.Logger, adc *akoov1alpha1.AKODeploymentConfig) error
+
+// CheckAviControllerConnectivity logs in to the AVI controller referenced by
+// the AKODeploymentConfig and fetches its version, the same way the webhook
+// does before accepting an AKODeploymentConfig
+func CheckAviControllerConnectivity(ctx context.Context, c client.Client, log logr.Logger, adc *akoov1alpha1.AKODeploymentConfig) error {
+	aviClient, err := aviclient.NewAviClientFromSecrets(c, ctx, log, adc.Spec.Controller,
+		adc.Spec.AdminCredentialRef.Name, adc.Spec.AdminCredentialRef.Namespace,
+		adc.Spec.CertificateAuthorityRef.Name, adc.Spec.CertificateAuthorityRef.Namespace,
+		adc.Spec.ControllerVersion)
+	if err != nil {
+		return err
+	}
+	_, err = aviClient.GetControllerVersion()
+	return err
+}
+
+// Checker periodically checks whether the AVI controller of each
+// AKODeploymentConfig is reachable and reports it through the
+// ako_operator_avi_controller_reachable metric. It doesn't gate the readiness
+// of the operator, which serves the webhooks needed to fix an
+// AKODeploymentConfig with an unreachable controller.
+type Checker struct {
+	Client   client.Client
+	Log      logr.Logger
+	Interval time.Duration
+
+	CheckConnectivity CheckFunc
+}
+
+// NewChecker returns a Checker using the default AVI controller connectivity
+// check
+func NewChecker(c client.Client, log logr.Logger, interval time.Duration) *Checker {
+	return &Checker{
+		Client:            c,
+		Log:               log,
+		Interval:          interval,
+		CheckConnectivity: CheckAviControllerConnectivity,
+	}
+}
+
+// Start runs the connectivity check until the context is done. It implements
+// manager.Runnable.
+func (r *Checker) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, r.refresh, r.Interval)
+	return nil
+}
+
+// NeedLeaderElection makes every replica run the check, since each of them
+// serves its own metrics
+func (r *Checker) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Checker) refresh(ctx context.Context) {
+	adcList := &akoov1alpha1.AKODeploymentConfigList{}
+	if err := r.Client.List(ctx, adcList); err != nil {
+		r.Log.Error(err, "Failed to list AKODeploymentConfig objects")
+		return
+	}
+	reachable := make(map[string]bool, len(adcList.Items))
+	for i := range adcList.Items {
+		adc := &adcList.Items[i]
+		log := r.Log.WithValues("akodeploymentconfig_name", adc.Name)
+		err := r.CheckConnectivity(ctx, r.Client, log, adc)
+		if err != nil {
+			log.Info("AVI controller is not reachable", "controller", adc.Spec.Controller, "error", err.Error())
+		}
+		reachable[adc.Name] = err == nil
+	}
+	metrics.SetAVIControllerReachability(reachable)
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package connectivity_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/connectivity"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func newFakeClient(controller string) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(akoov1alpha1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&akoov1alpha1.AKODeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-adc"},
+			Spec: akoov1alpha1.AKODeploymentConfigSpec{
+				Controller:        controller,
+				ControllerVersion: "20.1.3",
+				AdminCredentialRef: &akoov1alpha1.SecretRef{
+					Name:      "controller-credentials",
+					Namespace: "default",
+				},
+				CertificateAuthorityRef: &akoov1alpha1.SecretRef{
+					Name:      "controller-ca",
+					Namespace: "default",
+				},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "controller-credentials", Namespace: "default"},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("Admin!23"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "controller-ca", Namespace: "default"},
+		},
+	).Build()
+}
+
+// reachableValue returns the AVI controller reachability gauge of the
+// AKODeploymentConfig, and whether it's recorded
+func reachableValue(adc string) (float64, bool) {
+	families, err := ctrlmetrics.Registry.Gather()
+	Expect(err).ShouldNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "ako_operator_avi_controller_reachable" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "akodeploymentconfig" && l.GetValue() == adc {
+					return m.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+var _ = Describe("Checker", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		metrics.SetAVIControllerReachability(nil)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should report the AVI controller as unreachable", func() {
+		checker := connectivity.NewChecker(newFakeClient("127.0.0.1:1"), log.Log, time.Second)
+		// the AVI session keeps retrying an unreachable controller for minutes
+		// before giving up, so fail the connectivity check right away
+		checker.CheckConnectivity = func(context.Context, client.Client, logr.Logger, *akoov1alpha1.AKODeploymentConfig) error {
+			return errors.New("dial tcp 127.0.0.1:1: connect: connection refused")
+		}
+		go func() {
+			_ = checker.Start(ctx)
+		}()
+		Eventually(func() bool {
+			_, ok := reachableValue("test-adc")
+			return ok
+		}, 30*time.Second).Should(BeTrue())
+		value, _ := reachableValue("test-adc")
+		Expect(value).To(Equal(0.0))
+	})
+
+	It("should fail the connectivity check right away when the AVI controller port is invalid", func() {
+		c := newFakeClient("avi.example.com:70000")
+		adc := &akoov1alpha1.AKODeploymentConfig{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "test-adc"}, adc)).To(Succeed())
+		err := connectivity.CheckAviControllerConnectivity(ctx, c, log.Log, adc)
+		Expect(err).To(MatchError(ContainSubstring("invalid port")))
+	})
+
+	It("should report the AVI controller as reachable", func() {
+		aviServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/login"):
+				_, _ = w.Write([]byte("{}"))
+				return
+			case strings.HasSuffix(r.URL.Path, "/api/initial-data"):
+				_, _ = w.Write([]byte(`{"version":{"Version":"20.1.3"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer aviServer.Close()
+
+		checker := connectivity.NewChecker(newFakeClient(strings.TrimPrefix(aviServer.URL, "https://")), log.Log, time.Second)
+		go func() {
+			_ = checker.Start(ctx)
+		}()
+		Eventually(func() float64 {
+			value, _ := reachableValue("test-adc")
+			return value
+		}, 30*time.Second).Should(Equal(1.0))
+	})
+})
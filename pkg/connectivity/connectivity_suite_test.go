@@ -0,0 +1,16 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package connectivity_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestConnectivity(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Connectivity Suite")
+}
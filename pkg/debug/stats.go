@@ -0,0 +1,45 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package debug serves runtime statistics of AKO Operator, to help find the
+// goroutine and memory leaks of its long-running controllers
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+)
+
+// StatsPath is the path the statistics are served on
+const StatsPath = "/debug/stats"
+
+// Stats are the runtime statistics of AKO Operator
+type Stats struct {
+	// HeapAlloc is the number of bytes of allocated heap objects
+	HeapAlloc uint64 `json:"heapAlloc"`
+	// Goroutines is the number of goroutines
+	Goroutines int `json:"goroutines"`
+	// ActiveReconciles is the number of in-flight reconciles
+	ActiveReconciles int `json:"activeReconciles"`
+}
+
+// NewServeMux returns a ServeMux serving the Stats as JSON on StatsPath,
+// activeReconciles returns the number of in-flight reconciles
+func NewServeMux(activeReconciles func() int) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StatsPath, func(w http.ResponseWriter, _ *http.Request) {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+		stats := Stats{
+			HeapAlloc:        memStats.HeapAlloc,
+			Goroutines:       runtime.NumGoroutine(),
+			ActiveReconciles: activeReconciles(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	return mux
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStats(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewServer(NewServeMux(func() int { return 2 }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + StatsPath)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	g.Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+	var stats map[string]interface{}
+	g.Expect(json.NewDecoder(resp.Body).Decode(&stats)).To(Succeed())
+	g.Expect(stats).To(HaveKey("heapAlloc"))
+	g.Expect(stats["heapAlloc"]).To(BeNumerically(">", 0))
+	g.Expect(stats).To(HaveKey("goroutines"))
+	g.Expect(stats["goroutines"]).To(BeNumerically(">", 0))
+	g.Expect(stats).To(HaveKeyWithValue("activeReconciles", BeNumerically("==", 2)))
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package features defines the feature gates of AKO Operator. They are
+// registered with the default feature gate of k8s.io/apiserver, which is set
+// by the --feature-gates flag, so a gated code path checks
+// feature.DefaultFeatureGate.Enabled(features.<FeatureName>)
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// BGPSupport renders the BGP peering settings, enableRHI and
+	// bgpPeerLabels, of the AKODeploymentConfig in the AKO values
+	BGPSupport featuregate.Feature = "BGPSupport"
+
+	// IPv6DataNetwork allows the data network of an AKODeploymentConfig to be
+	// an IPv6 network, it can be disabled to only accept IPv4 data networks
+	IPv6DataNetwork featuregate.Feature = "IPv6DataNetwork"
+
+	// MultiVRF renders the NSX-T Tier1 logical router of the
+	// AKODeploymentConfig in the AKO values, so that the virtual services of
+	// each cluster are placed in the VRF of their own Tier1 router
+	MultiVRF featuregate.Feature = "MultiVRF"
+)
+
+func init() {
+	runtime.Must(feature.DefaultMutableFeatureGate.Add(defaultFeatureGates))
+}
+
+// defaultFeatureGates lists the feature gates of AKO Operator and their
+// default value. The beta features are enabled by default since they used to
+// be always enabled
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	BGPSupport:      {Default: true, PreRelease: featuregate.Beta},
+	IPv6DataNetwork: {Default: true, PreRelease: featuregate.Beta},
+	MultiVRF:        {Default: true, PreRelease: featuregate.Beta},
+}
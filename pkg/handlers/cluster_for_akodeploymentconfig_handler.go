@@ -28,10 +28,10 @@ func AkoDeploymentConfigForCluster(c client.Client, log logr.Logger) handler.Map
 		if !ok {
 			log.Error(errors.New("invalid type"),
 				"Expected to receive Cluster resource",
-				"actualType", fmt.Sprintf("%T", o))
+				"actual_type", fmt.Sprintf("%T", o))
 			return nil
 		}
-		logger := log.WithValues("cluster", cluster.Namespace+"/"+cluster.Name)
+		logger := log.WithValues("cluster_name", client.ObjectKeyFromObject(cluster).String())
 		if ako_operator.SkipCluster(cluster) {
 			logger.Info("Skipping cluster in handler")
 			return []reconcile.Request{}
@@ -202,4 +202,40 @@ var _ = Describe("AKODeploymentConfig Cluster Handler", func() {
 		})
 	})
 
+	When("the Cluster is created from a ClusterClass referencing an AKODeploymentConfig", func() {
+		BeforeEach(func() {
+			akodeploymentconfig1 := &akoov1alpha1.AKODeploymentConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test1",
+				},
+				Spec: akoov1alpha1.AKODeploymentConfigSpec{
+					ClusterSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test1": "hey",
+						},
+					},
+				},
+			}
+			clusterClass := &clusterv1.ClusterClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-class",
+					Namespace: "default",
+					Annotations: map[string]string{
+						akoov1alpha1.ClusterClassDefaultADCAnnotation: "test1",
+					},
+				},
+			}
+			Expect(fclient.Create(ctx, akodeploymentconfig1)).NotTo(HaveOccurred())
+			Expect(fclient.Create(ctx, clusterClass)).NotTo(HaveOccurred())
+
+			cluster.Spec.Topology = &clusterv1.Topology{Class: "test-class"}
+			input = cluster
+			Expect(fclient.Create(ctx, input)).NotTo(HaveOccurred())
+		})
+		It("should create one request for the referenced AKODeploymentConfig", func() {
+			Expect(len(requests)).To(Equal(1))
+			Expect(requests[0].Name).To(Equal("test1"))
+		})
+	})
+
 })
@@ -29,11 +29,11 @@ func MachinesForCluster(c client.Client, log logr.Logger) handler.MapFunc {
 		if !ok {
 			log.Error(errors.New("invalid type"),
 				"Expected to receive Cluster resource",
-				"actualType", fmt.Sprintf("%T", o))
+				"actual_type", fmt.Sprintf("%T", o))
 			return nil
 		}
 
-		logger := log.WithValues("cluster", cluster.Namespace+"/"+cluster.Name)
+		logger := log.WithValues("cluster_name", client.ObjectKeyFromObject(cluster).String())
 
 		if ako_operator.SkipCluster(cluster) {
 			logger.Info("Skipping cluster in handler")
@@ -52,7 +52,7 @@ func MachinesForCluster(c client.Client, log logr.Logger) handler.MapFunc {
 			return []reconcile.Request{}
 		}
 
-		log.V(3).Info("Finished listing machines for cluster", "cluster", cluster.Namespace+"/"+cluster.Name, "machines-count", len(machines.Items))
+		log.V(3).Info("Finished listing machines for cluster", "cluster", cluster.Namespace+"/"+cluster.Name, "machines_count", len(machines.Items))
 
 		// Create a reconcile request for each machine resource.
 		requests := []ctrl.Request{}
@@ -0,0 +1,40 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+)
+
+// AkoDeploymentConfigForClusterClass returns a handler map function for mapping ClusterClass
+// resources to the AkoDeploymentConfig referenced by its default akodeploymentconfig annotation
+func AkoDeploymentConfigForClusterClass(log logr.Logger) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		clusterClass, ok := o.(*clusterv1.ClusterClass)
+		if !ok {
+			log.Error(errors.New("invalid type"),
+				"Expected to receive ClusterClass resource",
+				"actual_type", fmt.Sprintf("%T", o))
+			return nil
+		}
+		logger := log.WithValues("cluster_class_name", client.ObjectKeyFromObject(clusterClass).String())
+
+		adcName, ok := clusterClass.Annotations[akoov1alpha1.ClusterClassDefaultADCAnnotation]
+		if !ok || adcName == "" {
+			return []reconcile.Request{}
+		}
+		logger.Info("clusterclass references akodeploymentconfig", "akodeploymentconfig", adcName)
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: adcName}}}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package handlers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("AKODeploymentConfig ClusterClass Handler", func() {
+	var (
+		akoDeploymentConfigMapFunc handler.MapFunc
+		requests                   []reconcile.Request
+		input                      client.Object
+		clusterClass               *clusterv1.ClusterClass
+	)
+	BeforeEach(func() {
+		clusterClass = &clusterv1.ClusterClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-class",
+				Namespace: "default",
+			},
+		}
+		input = clusterClass
+	})
+
+	JustBeforeEach(func() {
+		akoDeploymentConfigMapFunc = AkoDeploymentConfigForClusterClass(log.Log)
+		requests = akoDeploymentConfigMapFunc(input)
+	})
+
+	When("the ClusterClass doesn't have the default akodeploymentconfig annotation", func() {
+		It("should not create any request", func() {
+			Expect(len(requests)).To(Equal(0))
+		})
+	})
+
+	When("the ClusterClass references an AKODeploymentConfig", func() {
+		BeforeEach(func() {
+			clusterClass.Annotations = map[string]string{
+				akoov1alpha1.ClusterClassDefaultADCAnnotation: "test-adc",
+			}
+		})
+		It("should create one request for the referenced AKODeploymentConfig", func() {
+			Expect(len(requests)).To(Equal(1))
+			Expect(requests[0].Name).To(Equal("test-adc"))
+			Expect(requests[0].Namespace).To(BeEmpty())
+		})
+	})
+
+	When("the object is not a ClusterClass", func() {
+		BeforeEach(func() {
+			input = &corev1.Secret{}
+		})
+		It("should not create any request", func() {
+			Expect(requests).To(BeNil())
+		})
+	})
+})
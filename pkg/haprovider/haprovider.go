@@ -22,6 +22,7 @@ import (
 
 	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
 	ako_operator "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/ako-operator"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
 	akov1alpha1 "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/apis/ako/v1alpha1"
 )
 
@@ -178,7 +179,8 @@ func (r *HAProvider) annotateService(ctx context.Context, cluster *clusterv1.Clu
 	}
 
 	if _, ok := cluster.Labels[akoov1alpha1.TKGManagememtClusterRoleLabel]; ok {
-		if adcForCluster.Spec.ControlPlaneNetwork.CIDR != "" && adcForCluster.Spec.ControlPlaneNetwork.CIDR != adcForCluster.Spec.DataNetwork.CIDR {
+		spec := adcForCluster.EffectiveSpec(cluster.Labels)
+		if spec.ControlPlaneNetwork.CIDR != "" && spec.ControlPlaneNetwork.CIDR != spec.DataNetwork.CIDR {
 			if aviInfraSetting == nil {
 				return serviceAnnotation, errors.New("management cluster control plane network set, but corresponding AVIInfraSetting not found, requeue to wait for AVIInfraSetting created")
 			}
@@ -286,12 +288,18 @@ func (r *HAProvider) removeMachineIpFromEndpoints(endpoints *corev1.Endpoints, m
 		r.log.Info("currentEndpoints.Subsets is already empty, skip")
 		return
 	}
+	// the Machine addresses can be gone at deletion, the recorded ip is the one
+	// which was added to the Endpoints
+	recordedIP := machine.Annotations[akoov1alpha1.RecordedMachineIPAnnotation]
 	newAddresses := make([]corev1.EndpointAddress, 0)
 	for _, address := range endpoints.Subsets[0].Addresses {
 		// skip the machine should be deleted
 		if address.NodeName != nil && *address.NodeName == machine.Name {
 			continue
 		}
+		if recordedIP != "" && address.IP == recordedIP {
+			continue
+		}
 		newAddresses = append(newAddresses, address)
 	}
 	endpoints.Subsets[0].Addresses = newAddresses
@@ -322,22 +330,14 @@ func (r *HAProvider) addMachineIpToEndpoints(endpoints *corev1.Endpoints, machin
 		}
 	}
 	// add a new machine to Endpoints
-	for _, machineAddress := range machine.Status.Addresses {
-		if machineAddress.Type != clusterv1.MachineExternalIP {
-			continue
-		}
-		// check machineAddress.Address is valid
-		// only support IPv4 for now
-		if net.ParseIP(machineAddress.Address) != nil && net.ParseIP(machineAddress.Address).To4() != nil {
-			newAddress := corev1.EndpointAddress{
-				IP:       machineAddress.Address,
-				NodeName: &machine.Name,
-			}
-			endpoints.Subsets[0].Addresses = append(endpoints.Subsets[0].Addresses, newAddress)
-			break
-		} else {
-			r.log.Info(machineAddress.Address + " is not a valid IPv4 address")
-		}
+	// only support IPv4 for now
+	if ip := utils.GetMachineExternalIP(machine); ip != "" {
+		endpoints.Subsets[0].Addresses = append(endpoints.Subsets[0].Addresses, corev1.EndpointAddress{
+			IP:       ip,
+			NodeName: &machine.Name,
+		})
+	} else {
+		r.log.Info("machine " + machine.Name + " has no valid IPv4 external address")
 	}
 }
 
@@ -0,0 +1,156 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package leaderelection lets the in-flight reconciles complete when the
+// manager loses the leader election lease. The manager skips its graceful
+// shutdown in that case, so the reconciles would be killed when the operator
+// exits.
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultLeaseDuration is the leader election lease duration of the
+	// manager, which is the controller-runtime default
+	DefaultLeaseDuration = 15 * time.Second
+	// DefaultRenewDeadline is how long the leader retries to renew the lease
+	// before it gives up, which is the controller-runtime default
+	DefaultRenewDeadline = 10 * time.Second
+	// DefaultDeadline is the default time the in-flight reconciles are allowed to
+	// complete after the leader election lease is lost, it's below
+	// DefaultLeaseDuration - DefaultRenewDeadline
+	DefaultDeadline = 4 * time.Second
+)
+
+// ValidateDeadline returns an error when the in-flight reconciles could still
+// run once another replica acquires the lease, which can happen
+// leaseDuration - renewDeadline after the lease is lost
+func ValidateDeadline(deadline, leaseDuration, renewDeadline time.Duration) error {
+	if max := leaseDuration - renewDeadline; deadline >= max {
+		return fmt.Errorf("leader election deadline %s must be less than the lease duration %s minus the renew deadline %s",
+			deadline, leaseDuration, renewDeadline)
+	}
+	return nil
+}
+
+// DefaultTracker tracks the reconciles of the reconcilers wrapped by
+// Reconciler
+var DefaultTracker = NewTracker(DefaultDeadline)
+
+// Reconciler wraps r so its reconciles are tracked by DefaultTracker
+func Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return DefaultTracker.Reconciler(r)
+}
+
+// Tracker tracks the in-flight reconciles, so they can complete within
+// Deadline once the manager is stopped
+type Tracker struct {
+	// Deadline is how long the in-flight reconciles are allowed to complete
+	// after the manager is stopped, it must be set before reconciling
+	Deadline time.Duration
+
+	mu       sync.Mutex
+	draining bool
+	active   int
+	wg       sync.WaitGroup
+}
+
+// NewTracker returns a new Tracker with the given deadline
+func NewTracker(deadline time.Duration) *Tracker {
+	return &Tracker{Deadline: deadline}
+}
+
+// Reconciler wraps r so its reconciles are tracked. Their context is canceled
+// Deadline after the controller's context, and no new reconcile is started
+// once Wait is called.
+func (t *Tracker) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		t.mu.Lock()
+		if t.draining {
+			t.mu.Unlock()
+			// the new leader reconciles it
+			return reconcile.Result{Requeue: true}, nil
+		}
+		t.wg.Add(1)
+		t.active++
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			t.active--
+			t.mu.Unlock()
+			t.wg.Done()
+		}()
+
+		ctx, cancel := withGracePeriod(ctx, t.Deadline)
+		defer cancel()
+		return r.Reconcile(ctx, req)
+	})
+}
+
+// Active returns the number of in-flight reconciles
+func (t *Tracker) Active() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// Wait waits at most Deadline for the in-flight reconciles to complete, it
+// returns false when some are still running
+func (t *Tracker) Wait() bool {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(t.Deadline):
+		return false
+	}
+}
+
+// withGracePeriod returns a context with the values of parent, which is
+// canceled gracePeriod after parent is done
+func withGracePeriod(parent context.Context, gracePeriod time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(detachedContext{parent})
+	go func() {
+		select {
+		case <-parent.Done():
+		case <-ctx.Done():
+			return
+		}
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// detachedContext has the values of its parent but is never canceled with it
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
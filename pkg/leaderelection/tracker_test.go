@@ -0,0 +1,129 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type ctxKey struct{}
+
+// blockingReconciler blocks until release is closed, and reports the error of
+// its context when it completes
+type blockingReconciler struct {
+	started chan struct{}
+	release chan struct{}
+	ctxErr  chan error
+	value   chan interface{}
+}
+
+func newBlockingReconciler() *blockingReconciler {
+	return &blockingReconciler{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		ctxErr:  make(chan error, 1),
+		value:   make(chan interface{}, 1),
+	}
+}
+
+func (r *blockingReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	close(r.started)
+	r.value <- ctx.Value(ctxKey{})
+	<-r.release
+	r.ctxErr <- ctx.Err()
+	return reconcile.Result{}, nil
+}
+
+func TestTracker(t *testing.T) {
+	t.Run("lease lost mid-reconcile", func(t *testing.T) {
+		g := NewWithT(t)
+		tracker := NewTracker(time.Second)
+		inner := newBlockingReconciler()
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "logger"))
+
+		completed := make(chan struct{})
+		go func() {
+			defer close(completed)
+			_, _ = tracker.Reconciler(inner).Reconcile(ctx, reconcile.Request{})
+		}()
+		<-inner.started
+		g.Expect(<-inner.value).To(Equal("logger"))
+
+		// losing the lease cancels the context of the controllers
+		cancel()
+		time.AfterFunc(100*time.Millisecond, func() { close(inner.release) })
+		g.Expect(tracker.Wait()).To(BeTrue())
+		g.Expect(completed).To(BeClosed())
+		g.Expect(<-inner.ctxErr).NotTo(HaveOccurred())
+	})
+
+	t.Run("reconcile overruns the deadline", func(t *testing.T) {
+		g := NewWithT(t)
+		tracker := NewTracker(100 * time.Millisecond)
+		inner := newBlockingReconciler()
+		defer close(inner.release)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go func() {
+			_, _ = tracker.Reconciler(inner).Reconcile(ctx, reconcile.Request{})
+		}()
+		<-inner.started
+		cancel()
+		g.Expect(tracker.Wait()).To(BeFalse())
+	})
+
+	t.Run("in-flight reconciles are counted", func(t *testing.T) {
+		g := NewWithT(t)
+		tracker := NewTracker(time.Second)
+		inner := newBlockingReconciler()
+
+		completed := make(chan struct{})
+		go func() {
+			defer close(completed)
+			_, _ = tracker.Reconciler(inner).Reconcile(context.Background(), reconcile.Request{})
+		}()
+		<-inner.started
+		<-inner.value
+		g.Expect(tracker.Active()).To(Equal(1))
+
+		close(inner.release)
+		<-completed
+		g.Expect(tracker.Active()).To(BeZero())
+	})
+
+	t.Run("no reconcile is started while draining", func(t *testing.T) {
+		g := NewWithT(t)
+		tracker := NewTracker(100 * time.Millisecond)
+		g.Expect(tracker.Wait()).To(BeTrue())
+
+		inner := newBlockingReconciler()
+		res, err := tracker.Reconciler(inner).Reconcile(context.Background(), reconcile.Request{})
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
+		g.Expect(inner.started).NotTo(BeClosed())
+	})
+}
+
+func TestWithGracePeriod(t *testing.T) {
+	g := NewWithT(t)
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := withGracePeriod(parent, 100*time.Millisecond)
+	defer cancel()
+
+	cancelParent()
+	g.Consistently(ctx.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+	g.Eventually(ctx.Done(), time.Second).Should(BeClosed())
+}
+
+func TestValidateDeadline(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(ValidateDeadline(DefaultDeadline, DefaultLeaseDuration, DefaultRenewDeadline)).To(Succeed())
+	g.Expect(ValidateDeadline(5*time.Second, DefaultLeaseDuration, DefaultRenewDeadline)).NotTo(Succeed())
+	g.Expect(ValidateDeadline(30*time.Second, DefaultLeaseDuration, DefaultRenewDeadline)).NotTo(Succeed())
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+const (
+	// ConfigMapName is the ConfigMap the log verbosity of AKO Operator is
+	// read from
+	ConfigMapName = "ako-operator-config"
+	// LogLevelKey is the key of the log verbosity in the ConfigMap, see
+	// ParseLevel for its values
+	LogLevelKey = "logLevel"
+)
+
+// ConfigMapWatcher watches the ako-operator-config ConfigMap and sets the
+// verbosity of the DynamicLogger to its logLevel. The verbosity goes back to
+// the default one when the key or the ConfigMap is removed.
+type ConfigMapWatcher struct {
+	Config    *rest.Config
+	Scheme    *runtime.Scheme
+	Log       logr.Logger
+	Logger    *DynamicLogger
+	Namespace string
+}
+
+var _ toolscache.ResourceEventHandler = &ConfigMapWatcher{}
+
+// Start watches the ConfigMap until the context is done. It implements
+// manager.Runnable.
+func (w *ConfigMapWatcher) Start(ctx context.Context) error {
+	// only the ConfigMap is watched, the other ConfigMaps of the namespace
+	// aren't cached
+	c, err := cache.New(w.Config, cache.Options{
+		Scheme:    w.Scheme,
+		Namespace: w.Namespace,
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", ConfigMapName)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	informer, err := c.GetInformer(ctx, &corev1.ConfigMap{})
+	if err != nil {
+		return err
+	}
+	informer.AddEventHandler(w)
+	w.Log.Info("Watching the log level", "configmap_name", w.Namespace+"/"+ConfigMapName)
+	return c.Start(ctx)
+}
+
+// NeedLeaderElection makes every replica watch the ConfigMap, since each of
+// them has its own logger
+func (w *ConfigMapWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// OnAdd implements toolscache.ResourceEventHandler
+func (w *ConfigMapWatcher) OnAdd(obj interface{}) {
+	if configMap, ok := obj.(*corev1.ConfigMap); ok {
+		w.update(configMap)
+	}
+}
+
+// OnUpdate implements toolscache.ResourceEventHandler
+func (w *ConfigMapWatcher) OnUpdate(_, newObj interface{}) {
+	if configMap, ok := newObj.(*corev1.ConfigMap); ok {
+		w.update(configMap)
+	}
+}
+
+// OnDelete implements toolscache.ResourceEventHandler
+func (w *ConfigMapWatcher) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if configMap, ok := obj.(*corev1.ConfigMap); ok && w.isConfigMap(configMap) {
+		w.setLevel(w.Logger.defaultLevel)
+	}
+}
+
+func (w *ConfigMapWatcher) update(configMap *corev1.ConfigMap) {
+	if !w.isConfigMap(configMap) {
+		return
+	}
+	value, ok := configMap.Data[LogLevelKey]
+	if !ok {
+		w.setLevel(w.Logger.defaultLevel)
+		return
+	}
+	level, err := ParseLevel(value)
+	if err != nil {
+		// keep the current level, the ConfigMap is checked again when it's
+		// fixed
+		w.Log.Error(err, "Failed to parse the log level, keep the current one", "log_level", w.Logger.Level())
+		return
+	}
+	w.setLevel(level)
+}
+
+func (w *ConfigMapWatcher) setLevel(level int) {
+	if previous := w.Logger.Level(); previous != level {
+		w.Logger.SetLevel(level)
+		w.Log.Info("Log level changed", "previous_log_level", previous, "log_level", level)
+	}
+}
+
+func (w *ConfigMapWatcher) isConfigMap(configMap *corev1.ConfigMap) bool {
+	return configMap.Namespace == w.Namespace && configMap.Name == ConfigMapName
+}
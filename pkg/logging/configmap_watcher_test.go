@@ -0,0 +1,65 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestConfigMapWatcher(t *testing.T) {
+	g := NewWithT(t)
+
+	d := NewDynamicLogger(logr.Discard(), DefaultLevel)
+	w := &ConfigMapWatcher{Log: logr.Discard(), Logger: d, Namespace: "tkg-system-networking"}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "tkg-system-networking"},
+		Data:       map[string]string{LogLevelKey: "info"},
+	}
+
+	w.OnAdd(configMap)
+	g.Expect(d.Level()).To(Equal(0))
+
+	debugConfigMap := configMap.DeepCopy()
+	debugConfigMap.Data[LogLevelKey] = "debug"
+	w.OnUpdate(configMap, debugConfigMap)
+	g.Expect(d.Level()).To(Equal(DebugLevel))
+
+	t.Run("an invalid level keeps the current one", func(t *testing.T) {
+		g := NewWithT(t)
+		invalid := debugConfigMap.DeepCopy()
+		invalid.Data[LogLevelKey] = "verbose"
+		w.OnUpdate(debugConfigMap, invalid)
+		g.Expect(d.Level()).To(Equal(DebugLevel))
+	})
+
+	t.Run("other ConfigMaps are ignored", func(t *testing.T) {
+		g := NewWithT(t)
+		other := configMap.DeepCopy()
+		other.Name = "other"
+		w.OnAdd(other)
+		g.Expect(d.Level()).To(Equal(DebugLevel))
+	})
+
+	t.Run("the default level is restored when the key is removed", func(t *testing.T) {
+		g := NewWithT(t)
+		removed := debugConfigMap.DeepCopy()
+		delete(removed.Data, LogLevelKey)
+		w.OnUpdate(debugConfigMap, removed)
+		g.Expect(d.Level()).To(Equal(DefaultLevel))
+	})
+
+	t.Run("the default level is restored when the ConfigMap is deleted", func(t *testing.T) {
+		g := NewWithT(t)
+		w.OnUpdate(configMap, debugConfigMap)
+		g.Expect(d.Level()).To(Equal(DebugLevel))
+		w.OnDelete(toolscache.DeletedFinalStateUnknown{Obj: debugConfigMap})
+		g.Expect(d.Level()).To(Equal(DefaultLevel))
+	})
+}
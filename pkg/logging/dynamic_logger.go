@@ -0,0 +1,118 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logging lets the log verbosity of AKO Operator be changed while it's
+// running, without restarting the controllers
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// DefaultLevel is the verbosity AKO Operator logs at until a level is
+	// set, it's the one of the zap development mode the operator logs with
+	DefaultLevel = 1
+	// DebugLevel is the verbosity of the debug messages of AKO Operator
+	DebugLevel = 3
+	// MaxLevel is the highest verbosity which can be set, the wrapped logger
+	// must enable it for all the levels to be logged
+	MaxLevel = 10
+)
+
+// DynamicLogger wraps a logr.Logger, and only passes it the messages up to a
+// verbosity which can be changed at any time. The loggers derived from it with
+// WithName, WithValues or V all follow the changes.
+type DynamicLogger struct {
+	logger       logr.Logger
+	level        int32
+	defaultLevel int
+}
+
+// NewDynamicLogger returns a DynamicLogger wrapping logger, starting at level
+func NewDynamicLogger(logger logr.Logger, level int) *DynamicLogger {
+	d := &DynamicLogger{defaultLevel: level}
+	d.SetLevel(level)
+	sink := logger.GetSink()
+	// skip the frame of dynamicSink so the callers are reported
+	if callDepthSink, ok := sink.(logr.CallDepthLogSink); ok {
+		sink = callDepthSink.WithCallDepth(1)
+	}
+	d.logger = logr.New(&dynamicSink{sink: sink, level: &d.level})
+	return d
+}
+
+// Logger returns the logr.Logger whose verbosity is controlled by d
+func (d *DynamicLogger) Logger() logr.Logger {
+	return d.logger
+}
+
+// Level returns the current verbosity
+func (d *DynamicLogger) Level() int {
+	return int(atomic.LoadInt32(&d.level))
+}
+
+// SetLevel changes the verbosity the messages are logged up to
+func (d *DynamicLogger) SetLevel(level int) {
+	atomic.StoreInt32(&d.level, int32(level))
+}
+
+// ParseLevel parses a verbosity, either info, debug or a number between 0 and
+// MaxLevel
+func ParseLevel(s string) (int, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "info":
+		return 0, nil
+	case "debug":
+		return DebugLevel, nil
+	}
+	level, err := strconv.Atoi(s)
+	if err != nil || level < 0 || level > MaxLevel {
+		return 0, fmt.Errorf("invalid log level %q, should be info, debug or a number between 0 and %d", s, MaxLevel)
+	}
+	return level, nil
+}
+
+// dynamicSink is the logr.LogSink of a DynamicLogger, it shares the level of
+// the DynamicLogger with every sink derived from it
+type dynamicSink struct {
+	sink  logr.LogSink
+	level *int32
+}
+
+var _ logr.CallDepthLogSink = &dynamicSink{}
+
+// Init is a no-op, the wrapped sink is initialized by its own logger
+func (s *dynamicSink) Init(logr.RuntimeInfo) {}
+
+func (s *dynamicSink) Enabled(level int) bool {
+	return level <= int(atomic.LoadInt32(s.level)) && s.sink.Enabled(level)
+}
+
+func (s *dynamicSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.sink.Info(level, msg, keysAndValues...)
+}
+
+func (s *dynamicSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+}
+
+func (s *dynamicSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &dynamicSink{sink: s.sink.WithValues(keysAndValues...), level: s.level}
+}
+
+func (s *dynamicSink) WithName(name string) logr.LogSink {
+	return &dynamicSink{sink: s.sink.WithName(name), level: s.level}
+}
+
+func (s *dynamicSink) WithCallDepth(depth int) logr.LogSink {
+	if sink, ok := s.sink.(logr.CallDepthLogSink); ok {
+		return &dynamicSink{sink: sink.WithCallDepth(depth), level: s.level}
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/gomega"
+)
+
+func TestDynamicLogger(t *testing.T) {
+	g := NewWithT(t)
+
+	var messages []string
+	d := NewDynamicLogger(funcr.New(func(_, args string) {
+		messages = append(messages, args)
+	}, funcr.Options{Verbosity: MaxLevel}), DefaultLevel)
+	logger := d.Logger().WithName("controllers").WithValues("cluster_name", "test")
+
+	logger.V(DefaultLevel).Info("logged")
+	logger.V(DebugLevel).Info("not logged")
+	g.Expect(d.Level()).To(Equal(DefaultLevel))
+	g.Expect(messages).To(HaveLen(1))
+	g.Expect(messages[0]).To(ContainSubstring("logged"))
+	g.Expect(messages[0]).To(ContainSubstring("cluster_name"))
+
+	// the loggers derived before the change follow it
+	d.SetLevel(DebugLevel)
+	logger.V(DebugLevel).Info("debug")
+	g.Expect(messages).To(HaveLen(2))
+	g.Expect(messages[1]).To(ContainSubstring("debug"))
+
+	d.SetLevel(0)
+	logger.V(1).Info("not logged")
+	logger.Info("info")
+	logger.Error(nil, "error")
+	g.Expect(messages).To(HaveLen(4))
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		level   int
+		invalid bool
+	}{
+		{value: "info", level: 0},
+		{value: "debug", level: DebugLevel},
+		{value: " Debug ", level: DebugLevel},
+		{value: "5", level: 5},
+		{value: "-1", invalid: true},
+		{value: "11", invalid: true},
+		{value: "verbose", invalid: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			g := NewWithT(t)
+			level, err := ParseLevel(tc.value)
+			if tc.invalid {
+				g.Expect(err).Should(HaveOccurred())
+				return
+			}
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(level).To(Equal(tc.level))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ConditionTransition is a change of the status of a condition
+type ConditionTransition struct {
+	Type     clusterv1.ConditionType
+	From     corev1.ConditionStatus
+	To       corev1.ConditionStatus
+	Duration time.Duration
+}
+
+// conditionState is the last seen status of a condition, and since when the
+// condition has it
+type conditionState struct {
+	status corev1.ConditionStatus
+	since  time.Time
+}
+
+// ConditionTransitionTracker records the last seen status of the conditions of
+// each object, and reports how long a condition had its previous status when
+// it changes. The zero value is ready to use.
+type ConditionTransitionTracker struct {
+	// states maps an object key to the map[clusterv1.ConditionType]conditionState
+	// of its conditions the last time they were tracked
+	states sync.Map
+}
+
+// Track compares the conditions of the object identified by key with the ones
+// seen last time, and logs and records in the reconcile duration histogram of
+// controller the transitions. Nothing is reported the first time an object
+// or a condition is seen. The transitions are returned in the order of conditions.
+func (t *ConditionTransitionTracker) Track(log logr.Logger, controller, key string, conditions clusterv1.Conditions) []ConditionTransition {
+	now := time.Now()
+	previous := map[clusterv1.ConditionType]conditionState{}
+	if v, ok := t.states.Load(key); ok {
+		previous = v.(map[clusterv1.ConditionType]conditionState)
+	}
+
+	var transitions []ConditionTransition
+	current := make(map[clusterv1.ConditionType]conditionState, len(conditions))
+	for _, c := range conditions {
+		since := c.LastTransitionTime.Time
+		if since.IsZero() {
+			since = now
+		}
+		state := conditionState{status: c.Status, since: since}
+		if last, ok := previous[c.Type]; ok {
+			if last.status == c.Status {
+				state = last
+			} else {
+				transition := ConditionTransition{
+					Type:     c.Type,
+					From:     last.status,
+					To:       c.Status,
+					Duration: since.Sub(last.since),
+				}
+				transitions = append(transitions, transition)
+				log.Info("Condition transitioned", "condition", transition.Type,
+					"from", transition.From, "to", transition.To, "duration", transition.Duration.String())
+				reconcileDuration.WithLabelValues(controller, string(transition.To), string(transition.Type)).
+					Observe(transition.Duration.Seconds())
+			}
+		}
+		current[c.Type] = state
+	}
+	t.states.Store(key, current)
+	return transitions
+}
+
+// Forget drops the conditions seen for the object identified by key, it's
+// meant to be called once the object is gone
+func (t *ConditionTransitionTracker) Forget(key string) {
+	t.states.Delete(key)
+}
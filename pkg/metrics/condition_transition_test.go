@@ -0,0 +1,68 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Condition transition tracker", func() {
+	var (
+		tracker *metrics.ConditionTransitionTracker
+		start   time.Time
+	)
+
+	condition := func(status corev1.ConditionStatus, lastTransitionTime time.Time) clusterv1.Conditions {
+		return clusterv1.Conditions{{
+			Type:               "AKODeployed",
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(lastTransitionTime),
+		}}
+	}
+
+	BeforeEach(func() {
+		tracker = &metrics.ConditionTransitionTracker{}
+		start = time.Now().Add(-time.Minute)
+	})
+
+	It("should not report a transition the first time a condition is seen", func() {
+		Expect(tracker.Track(log.Log, "mock-first-seen", "default/adc", condition(corev1.ConditionFalse, start))).To(BeEmpty())
+	})
+
+	It("should detect a False to True transition", func() {
+		tracker.Track(log.Log, "mock-transition", "default/adc", condition(corev1.ConditionFalse, start))
+		Expect(tracker.Track(log.Log, "mock-transition", "default/adc", condition(corev1.ConditionFalse, start))).To(BeEmpty())
+
+		transitions := tracker.Track(log.Log, "mock-transition", "default/adc", condition(corev1.ConditionTrue, start.Add(42*time.Second)))
+		Expect(transitions).To(Equal([]metrics.ConditionTransition{{
+			Type:     "AKODeployed",
+			From:     corev1.ConditionFalse,
+			To:       corev1.ConditionTrue,
+			Duration: 42 * time.Second,
+		}}))
+		Expect(sampleCount("mock-transition", string(corev1.ConditionTrue), "AKODeployed")).To(Equal(uint64(1)))
+	})
+
+	It("should record a transition after hours in a finite bucket", func() {
+		tracker.Track(log.Log, "mock-long-transition", "default/adc", condition(corev1.ConditionFalse, start))
+		Expect(tracker.Track(log.Log, "mock-long-transition", "default/adc", condition(corev1.ConditionTrue, start.Add(3*time.Hour)))).To(HaveLen(1))
+		count, finiteCount := histogramCounts("mock-long-transition", string(corev1.ConditionTrue), "AKODeployed")
+		Expect(count).To(Equal(uint64(1)))
+		Expect(finiteCount).To(Equal(uint64(1)))
+	})
+
+	It("should forget the conditions of a deleted object", func() {
+		tracker.Track(log.Log, "mock-forget", "default/adc", condition(corev1.ConditionFalse, start))
+		tracker.Forget("default/adc")
+		Expect(tracker.Track(log.Log, "mock-forget", "default/adc", condition(corev1.ConditionTrue, start))).To(BeEmpty())
+	})
+})
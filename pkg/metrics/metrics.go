@@ -0,0 +1,119 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// OutcomeSuccess is recorded when a reconcile finished without error
+	OutcomeSuccess = "success"
+	// OutcomeError is recorded when a reconcile returned an error
+	OutcomeError = "error"
+	// OutcomeSkipped is recorded when a reconcile returned early without
+	// doing any work
+	OutcomeSkipped = "skipped"
+)
+
+// reconcileDuration also records how long a condition kept its previous
+// status when it transitions, in which case condition_transition is the
+// condition type and outcome its new status. condition_transition is empty
+// for reconciles. Its buckets go from 5ms to about 6h, so that both the
+// reconciles and the conditions which keep their status for hours are
+// recorded in a finite bucket.
+var reconcileDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "ako_operator_reconcile_duration_seconds",
+		Help:    "Duration of a reconcile in seconds, per controller and outcome, or of a condition status before its transition",
+		Buckets: prometheus.ExponentialBuckets(0.005, 4, 12),
+	},
+	[]string{"controller", "outcome", "condition_transition"},
+)
+
+// buildInfo is always 1, the build metadata of AKO Operator is in its labels
+var buildInfo = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "ako_operator_build_info",
+		Help: "Build metadata of AKO Operator, the value is always 1",
+	},
+	[]string{"version", "git_commit", "build_date"},
+)
+
+// activeGoroutines is updated by UpdateActiveGoroutines, a steady growth
+// hints at a goroutine leak
+var activeGoroutines = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "ako_operator_active_goroutines",
+		Help: "Number of goroutines of AKO Operator",
+	},
+)
+
+// aviControllerReachable is set by SetAVIControllerReachability to whether the
+// AVI controller of each AKODeploymentConfig is reachable
+var aviControllerReachable = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "ako_operator_avi_controller_reachable",
+		Help: "Whether the AVI controller of an AKODeploymentConfig is reachable, 1 if it is, 0 otherwise",
+	},
+	[]string{"akodeploymentconfig"},
+)
+
+// ActiveGoroutinesUpdateInterval is how often UpdateActiveGoroutines updates
+// the goroutine gauge
+const ActiveGoroutinesUpdateInterval = 30 * time.Second
+
+func init() {
+	// register to the controller-runtime registry which is served by the
+	// manager's metrics server
+	metrics.Registry.MustRegister(reconcileDuration, buildInfo, activeGoroutines, aviControllerReachable)
+	buildInfo.WithLabelValues(version.Version, version.GitCommit, version.BuildDate).Set(1)
+}
+
+// ObserveReconcileDuration records the time elapsed since start for the given
+// controller, it's meant to be deferred at the beginning of Reconcile
+func ObserveReconcileDuration(controller string, start time.Time, skipped bool, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	} else if skipped {
+		outcome = OutcomeSkipped
+	}
+	reconcileDuration.WithLabelValues(controller, outcome, "").Observe(time.Since(start).Seconds())
+}
+
+// SetAVIControllerReachability replaces the AVI controller reachability gauge
+// with the given reachability of the AVI controller of each
+// AKODeploymentConfig, so the deleted ones are dropped
+func SetAVIControllerReachability(reachable map[string]bool) {
+	aviControllerReachable.Reset()
+	for adc, ok := range reachable {
+		value := 0.0
+		if ok {
+			value = 1
+		}
+		aviControllerReachable.WithLabelValues(adc).Set(value)
+	}
+}
+
+// UpdateActiveGoroutines sets the goroutine gauge to the number of goroutines
+// every interval until ctx is done
+func UpdateActiveGoroutines(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		activeGoroutines.Set(float64(runtime.NumGoroutine()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
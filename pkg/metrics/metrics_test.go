@@ -0,0 +1,98 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/metrics"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/version"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// sampleCount returns the number of observations recorded in the reconcile
+// duration histogram for the given labels
+func sampleCount(controller, outcome, conditionTransition string) uint64 {
+	count, _ := histogramCounts(controller, outcome, conditionTransition)
+	return count
+}
+
+// histogramCounts returns the number of observations recorded in the
+// reconcile duration histogram for the given labels, and how many of them are
+// in its finite buckets
+func histogramCounts(controller, outcome, conditionTransition string) (uint64, uint64) {
+	families, err := ctrlmetrics.Registry.Gather()
+	Expect(err).ShouldNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "ako_operator_reconcile_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["controller"] == controller && labels["outcome"] == outcome &&
+				labels["condition_transition"] == conditionTransition {
+				buckets := m.GetHistogram().GetBucket()
+				return m.GetHistogram().GetSampleCount(), buckets[len(buckets)-1].GetCumulativeCount()
+			}
+		}
+	}
+	return 0, 0
+}
+
+func mockReconcile(controller string, skipped bool, err error) (reterr error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveReconcileDuration(controller, start, skipped, reterr)
+	}()
+	return err
+}
+
+var _ = Describe("Reconcile duration metric", func() {
+	It("should record one observation with success outcome", func() {
+		Expect(mockReconcile("mock-success", false, nil)).To(Succeed())
+		Expect(sampleCount("mock-success", metrics.OutcomeSuccess, "")).To(Equal(uint64(1)))
+		Expect(sampleCount("mock-success", metrics.OutcomeError, "")).To(Equal(uint64(0)))
+	})
+
+	It("should record one observation with error outcome", func() {
+		Expect(mockReconcile("mock-error", true, errors.New("failed"))).NotTo(Succeed())
+		Expect(sampleCount("mock-error", metrics.OutcomeError, "")).To(Equal(uint64(1)))
+		Expect(sampleCount("mock-error", metrics.OutcomeSkipped, "")).To(Equal(uint64(0)))
+	})
+
+	It("should record one observation with skipped outcome", func() {
+		Expect(mockReconcile("mock-skipped", true, nil)).To(Succeed())
+		Expect(sampleCount("mock-skipped", metrics.OutcomeSkipped, "")).To(Equal(uint64(1)))
+	})
+})
+
+var _ = Describe("Build info metric", func() {
+	It("should expose the build metadata as labels", func() {
+		families, err := ctrlmetrics.Registry.Gather()
+		Expect(err).ShouldNot(HaveOccurred())
+		var labels map[string]string
+		for _, family := range families {
+			if family.GetName() != "ako_operator_build_info" {
+				continue
+			}
+			Expect(family.GetMetric()).To(HaveLen(1))
+			Expect(family.GetMetric()[0].GetGauge().GetValue()).To(Equal(float64(1)))
+			labels = map[string]string{}
+			for _, l := range family.GetMetric()[0].GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+		}
+		Expect(labels).To(Equal(map[string]string{
+			"version":    version.Version,
+			"git_commit": version.GitCommit,
+			"build_date": version.BuildDate,
+		}))
+	})
+})
@@ -0,0 +1,64 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer of the AKO Operator spans, it's also
+// the service name of the exported spans
+const TracerName = "ako-operator"
+
+// Setup exports the spans to the OTLP/HTTP collector at endpoint, which is a
+// host:port served over HTTPS, or a http:// URL for a plain HTTP collector.
+// The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{}
+	if strings.HasPrefix(endpoint, "http://") {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(TracerName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// StartReconcileSpan starts the span of a reconcile named name, the returned
+// context is meant to be passed to everything the reconcile calls. Spans are
+// dropped when Setup isn't called.
+func StartReconcileSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan ends the span, marking it failed with an error attribute when err
+// isn't nil
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(attribute.String("error", err.Error()))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/cluster"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/controllers/machine"
+)
+
+func TestReconcileSpans(t *testing.T) {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	testMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+	}
+	testcases := []struct {
+		name          string
+		obj           client.Object
+		initObjs      []client.Object
+		newReconciler func(c client.Client) reconcile.Reconciler
+		spanName      string
+		attribute     string
+	}{
+		{
+			name: "cluster reconciler",
+			obj: &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+			},
+			newReconciler: func(c client.Client) reconcile.Reconciler {
+				return &cluster.ClusterReconciler{Client: c, Log: log.Log, Scheme: scheme}
+			},
+			spanName:  "ClusterReconciler.Reconcile",
+			attribute: "cluster_name",
+		},
+		{
+			name:     "machine reconciler",
+			obj:      testMachine,
+			initObjs: []client.Object{testMachine},
+			newReconciler: func(c client.Client) reconcile.Reconciler {
+				return &machine.MachineReconciler{Client: c, Log: log.Log, Scheme: scheme}
+			},
+			spanName:  "MachineReconciler.Reconcile",
+			attribute: "machine_name",
+		},
+	}
+
+	// withExporter records the spans of f in an in-memory exporter
+	withExporter := func(f func()) tracetest.SpanStubs {
+		exporter := tracetest.NewInMemoryExporter()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+		defer otel.SetTracerProvider(previous)
+		f()
+		return exporter.GetSpans()
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name+" should create a span for each reconcile", func(t *testing.T) {
+			g := NewWithT(t)
+			reconciler := tc.newReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.initObjs...).Build())
+			spans := withExporter(func() {
+				for i := 0; i < 2; i++ {
+					_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tc.obj)})
+					g.Expect(err).ShouldNot(HaveOccurred())
+				}
+			})
+
+			g.Expect(spans).To(HaveLen(2))
+			for _, span := range spans {
+				g.Expect(span.Name).To(Equal(tc.spanName))
+				g.Expect(span.Status.Code).NotTo(Equal(codes.Error))
+			}
+		})
+
+		t.Run(tc.name+" should mark the span of a failed reconcile with the error", func(t *testing.T) {
+			g := NewWithT(t)
+			// the kind of the object isn't registered, so getting it fails
+			reconciler := tc.newReconciler(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+			spans := withExporter(func() {
+				_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tc.obj)})
+				g.Expect(err).Should(HaveOccurred())
+			})
+
+			g.Expect(spans).To(HaveLen(1))
+			g.Expect(spans[0].Status.Code).To(Equal(codes.Error))
+			var attributes []string
+			for _, attr := range spans[0].Attributes {
+				attributes = append(attributes, string(attr.Key))
+			}
+			g.Expect(attributes).To(ContainElements(tc.attribute, "error"))
+		})
+	}
+}
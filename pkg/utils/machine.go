@@ -0,0 +1,47 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"net"
+
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// GetMachineIP returns the IP of the Machine regardless of the infrastructure
+// provider. It checks, in order, the InternalIP in the Machine status and the
+// MachineIPAnnotation, the Machine name isn't resolved.
+func GetMachineIP(machine *clusterv1.Machine) (string, error) {
+	for _, address := range machine.Status.Addresses {
+		if address.Type == clusterv1.MachineInternalIP && address.Address != "" {
+			return address.Address, nil
+		}
+	}
+
+	if ip, ok := machine.Annotations[akoov1alpha1.MachineIPAnnotation]; ok {
+		if net.ParseIP(ip) == nil {
+			return "", fmt.Errorf("machine %s/%s has an invalid %s annotation %q", machine.Namespace, machine.Name, akoov1alpha1.MachineIPAnnotation, ip)
+		}
+		return ip, nil
+	}
+
+	return "", fmt.Errorf("failed to get the IP of machine %s/%s: no InternalIP nor %s annotation", machine.Namespace, machine.Name, akoov1alpha1.MachineIPAnnotation)
+}
+
+// GetMachineExternalIP returns the first IPv4 ExternalIP in the Machine
+// status, which is the address the HA provider adds to the control plane
+// Endpoints. It returns "" when the Machine has none.
+func GetMachineExternalIP(machine *clusterv1.Machine) string {
+	for _, address := range machine.Status.Addresses {
+		if address.Type != clusterv1.MachineExternalIP {
+			continue
+		}
+		if ip := net.ParseIP(address.Address); ip != nil && ip.To4() != nil {
+			return address.Address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils_test
+
+import (
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	akoov1alpha1 "github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/api/v1alpha1"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = ginkgo.Describe("GetMachineIP", func() {
+	var machine *clusterv1.Machine
+
+	ginkgo.BeforeEach(func() {
+		machine = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-machine",
+				Namespace: "default",
+			},
+		}
+	})
+
+	ginkgo.It("should return the InternalIP of the machine status", func() {
+		machine.Status.Addresses = clusterv1.MachineAddresses{
+			{Type: clusterv1.MachineExternalIP, Address: "192.168.0.1"},
+			{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"},
+		}
+		machine.Annotations = map[string]string{akoov1alpha1.MachineIPAnnotation: "10.0.0.2"}
+		ip, err := utils.GetMachineIP(machine)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ip).To(Equal("10.0.0.1"))
+	})
+
+	ginkgo.It("should fall back to the machine IP annotation", func() {
+		machine.Status.Addresses = clusterv1.MachineAddresses{
+			{Type: clusterv1.MachineExternalIP, Address: "192.168.0.1"},
+		}
+		machine.Annotations = map[string]string{akoov1alpha1.MachineIPAnnotation: "10.0.0.2"}
+		ip, err := utils.GetMachineIP(machine)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ip).To(Equal("10.0.0.2"))
+	})
+
+	ginkgo.It("should fail with an invalid machine IP annotation", func() {
+		machine.Annotations = map[string]string{akoov1alpha1.MachineIPAnnotation: "not-an-ip"}
+		_, err := utils.GetMachineIP(machine)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	ginkgo.It("should fail when the machine has no InternalIP nor machine IP annotation", func() {
+		machine.Status.Addresses = clusterv1.MachineAddresses{
+			{Type: clusterv1.MachineExternalIP, Address: "192.168.0.1"},
+		}
+		_, err := utils.GetMachineIP(machine)
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
+var _ = ginkgo.Describe("GetMachineExternalIP", func() {
+	ginkgo.It("should return the first IPv4 ExternalIP of the machine status", func() {
+		machine := &clusterv1.Machine{
+			Status: clusterv1.MachineStatus{
+				Addresses: clusterv1.MachineAddresses{
+					{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"},
+					{Type: clusterv1.MachineExternalIP, Address: "fd01:3:4:2877:250:56ff:feb4:adaf"},
+					{Type: clusterv1.MachineExternalIP, Address: "192.168.0.1"},
+				},
+			},
+		}
+		Expect(utils.GetMachineExternalIP(machine)).To(Equal("192.168.0.1"))
+	})
+
+	ginkgo.It("should return empty when the machine has no IPv4 ExternalIP", func() {
+		machine := &clusterv1.Machine{
+			Status: clusterv1.MachineStatus{
+				Addresses: clusterv1.MachineAddresses{
+					{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"},
+					{Type: clusterv1.MachineExternalIP, Address: "test123"},
+				},
+			},
+		}
+		Expect(utils.GetMachineExternalIP(machine)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,47 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PatchConflictBackoff is the backoff of PatchWithRetry, it retries up to 3
+// times after the first conflict
+var PatchConflictBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: 10 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
+// PatchWithRetry patches obj with the patch helper, and retries the patch when
+// it fails with a conflict, e.g. another controller updates the same object
+func PatchWithRetry(ctx context.Context, patchHelper *patch.Helper, obj client.Object, opts ...patch.Option) error {
+	return retry.OnError(PatchConflictBackoff, isConflict, func() error {
+		return patchHelper.Patch(ctx, obj, opts...)
+	})
+}
+
+// isConflict checks if err is a conflict, the patch helper aggregates the
+// errors of patching the different parts of the object
+func isConflict(err error) bool {
+	if agg, ok := err.(kerrors.Aggregate); ok {
+		for _, e := range agg.Errors() {
+			if apierrors.IsConflict(e) {
+				return true
+			}
+		}
+		return false
+	}
+	return apierrors.IsConflict(err)
+}
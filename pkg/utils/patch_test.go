@@ -0,0 +1,81 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package utils_test
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware-tanzu/load-balancer-operator-for-kubernetes/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// conflictClient fails the first conflicts patches with a conflict
+type conflictClient struct {
+	client.Client
+	conflicts int
+	patches   int
+}
+
+func (c *conflictClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patches++
+	if c.patches <= c.conflicts {
+		return apierrors.NewConflict(schema.GroupResource{Group: clusterv1.GroupVersion.Group, Resource: "clusters"}, obj.GetName(), nil)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+var _ = ginkgo.Describe("Test patch with retry", func() {
+	var (
+		ctx     context.Context
+		fclient *conflictClient
+		cluster *clusterv1.Cluster
+	)
+
+	patchLabel := func() error {
+		patchHelper, err := patch.NewHelper(cluster, fclient)
+		Expect(err).ShouldNot(HaveOccurred())
+		cluster.Labels = map[string]string{"foo": "bar"}
+		return utils.PatchWithRetry(ctx, patchHelper, cluster)
+	}
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+		}
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		fclient = &conflictClient{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+		}
+	})
+
+	ginkgo.It("should succeed after conflicts", func() {
+		fclient.conflicts = 2
+		Expect(patchLabel()).To(Succeed())
+		Expect(fclient.patches).To(Equal(3))
+
+		obj := &clusterv1.Cluster{}
+		Expect(fclient.Get(ctx, client.ObjectKeyFromObject(cluster), obj)).To(Succeed())
+		Expect(obj.Labels).To(HaveKeyWithValue("foo", "bar"))
+	})
+
+	ginkgo.It("should give up after 3 retries", func() {
+		fclient.conflicts = 10
+		Expect(patchLabel()).NotTo(Succeed())
+		Expect(fclient.patches).To(Equal(4))
+	})
+})
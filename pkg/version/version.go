@@ -0,0 +1,22 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package version holds the build metadata of AKO Operator, set at build
+// time through -ldflags "-X"
+package version
+
+import "fmt"
+
+var (
+	// Version is the build version of AKO Operator
+	Version = "dev"
+	// GitCommit is the git commit AKO Operator is built from
+	GitCommit = "unknown"
+	// BuildDate is the date AKO Operator is built at, in RFC3339 format
+	BuildDate = "unknown"
+)
+
+// String returns the build metadata printed by the --version flag
+func String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", Version, GitCommit, BuildDate)
+}
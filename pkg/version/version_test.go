@@ -0,0 +1,22 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package version
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestString(t *testing.T) {
+	g := NewWithT(t)
+
+	defer func(version, gitCommit, buildDate string) {
+		Version, GitCommit, BuildDate = version, gitCommit, buildDate
+	}(Version, GitCommit, BuildDate)
+	Version, GitCommit, BuildDate = "1.9.0", "4fa6db9", "2022-11-02T10:00:00Z"
+
+	g.Expect(String()).NotTo(BeEmpty())
+	g.Expect(String()).To(Equal("1.9.0 (commit 4fa6db9, built 2022-11-02T10:00:00Z)"))
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// rbacWildcardAllowlist lists the "<role>/<resource>" pairs which are allowed
+// to use the "*" wildcard, a "*" resource is listed as "<role>/*"
+var rbacWildcardAllowlist = map[string]bool{}
+
+// TestVerifyRBAC fails when a role in config/rbac is granted a "*" verb or
+// resource which isn't in rbacWildcardAllowlist
+func TestVerifyRBAC(t *testing.T) {
+	g := NewWithT(t)
+
+	files, err := filepath.Glob(filepath.Join("config", "rbac", "*.yaml"))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(files).NotTo(BeEmpty())
+
+	var roles int
+	for _, file := range files {
+		for _, role := range loadRoles(g, file) {
+			roles++
+			for _, rule := range role.Rules {
+				for _, resource := range rule.Resources {
+					key := role.Name + "/" + resource
+					if resource == rbacv1.ResourceAll && !rbacWildcardAllowlist[key] {
+						t.Errorf("%s: role %s is granted all the resources of api groups %v", file, role.Name, rule.APIGroups)
+					}
+					for _, verb := range rule.Verbs {
+						if verb == rbacv1.VerbAll && !rbacWildcardAllowlist[key] {
+							t.Errorf("%s: role %s is granted all the verbs on %s", file, role.Name, resource)
+						}
+					}
+				}
+			}
+		}
+	}
+	// the operator ClusterRole must have been checked
+	g.Expect(roles).NotTo(BeZero())
+}
+
+// loadRoles returns the Roles and ClusterRoles defined in the file, as
+// ClusterRoles since only their rules are checked
+func loadRoles(g *WithT, file string) []rbacv1.ClusterRole {
+	f, err := os.Open(file)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer f.Close()
+
+	var roles []rbacv1.ClusterRole
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		role := rbacv1.ClusterRole{}
+		if err := decoder.Decode(&role); err != nil {
+			if errors.Is(err, io.EOF) {
+				return roles
+			}
+			g.Expect(err).ShouldNot(HaveOccurred())
+		}
+		if role.Kind == "ClusterRole" || role.Kind == "Role" {
+			roles = append(roles, role)
+		}
+	}
+}